@@ -0,0 +1,21 @@
+// Package repository 跨包静态装配示例中被依赖的包,bean在本包中注册
+package repository
+
+import (
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+)
+
+type Repository interface {
+	Find(id int) string
+}
+
+type MemoryRepository struct{}
+
+func (*MemoryRepository) Find(id int) string {
+	return "memory"
+}
+
+func Register(app *ioc.Application) {
+	app.RegisterBeans(core.NewBean(&MemoryRepository{}))
+}
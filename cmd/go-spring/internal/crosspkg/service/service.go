@@ -0,0 +1,17 @@
+// Package service 跨包静态装配示例,依赖repository包中注册的bean,zz_generated_wire.go由go-spring gen生成
+package service
+
+import (
+	"github.com/kgip/go-spring/cmd/go-spring/internal/crosspkg/repository"
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+)
+
+type Service struct {
+	Repo repository.Repository
+}
+
+func Register(app *ioc.Application) {
+	repository.Register(app)
+	app.RegisterBeans(core.NewBean(&Service{}))
+}
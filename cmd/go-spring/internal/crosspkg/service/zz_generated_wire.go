@@ -0,0 +1,31 @@
+// Code generated by go-spring gen. DO NOT EDIT.
+
+package service
+
+import (
+	"github.com/kgip/go-spring/cmd/go-spring/internal/crosspkg/repository"
+	"github.com/kgip/go-spring/core"
+)
+
+// GeneratedBeans go-spring gen静态装配的bean,按注册顺序排列
+type GeneratedBeans struct {
+	MemoryRepository *repository.MemoryRepository //MemoryRepository
+	Service          *Service                     //Service
+}
+
+// WireBeans 按依赖顺序创建并装配bean,依赖关系与容器注入相同,不执行bean处理器;返回的清理方法按创建顺序的逆序调用工厂方法返回的清理方法
+func WireBeans(c *core.Container) (*GeneratedBeans, func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+	memoryRepository := &repository.MemoryRepository{}
+	service := &Service{}
+	service.Repo = memoryRepository
+	return &GeneratedBeans{
+		MemoryRepository: memoryRepository,
+		Service:          service,
+	}, cleanup, nil
+}
@@ -0,0 +1,106 @@
+// Package example 静态装配生成器的示例,zz_generated_wire.go由go-spring gen生成
+package example
+
+import (
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+)
+
+type Store interface {
+	Get(key string) string
+}
+
+type MemoryStore struct{}
+
+func (*MemoryStore) Get(key string) string {
+	return "memory:" + key
+}
+
+type RedisStore struct {
+	Addr string
+}
+
+func (s *RedisStore) Get(key string) string {
+	return "redis:" + key
+}
+
+type Handler interface {
+	Handle() string
+}
+
+type HelloHandler struct{}
+
+func (HelloHandler) Handle() string {
+	return "hello"
+}
+
+// ByeHandler 以bean标签中的名称注册
+type ByeHandler struct {
+	core.Component `bean:"bye"`
+}
+
+func (*ByeHandler) GetPriority() int {
+	return 10
+}
+
+func (*ByeHandler) Handle() string {
+	return "bye"
+}
+
+type Client struct {
+	Store Store
+}
+
+// NewClient 依赖primary store的客户端,返回清理方法
+func NewClient(store Store) (*Client, func(), error) {
+	return &Client{Store: store}, func() { Closed++ }, nil
+}
+
+// Closed 客户端被清理的次数
+var Closed int
+
+type Service struct {
+	Client    *Client
+	Cache     Store              `qualifier:"redis"`
+	Handlers  []Handler          //按优先级排序
+	ByName    map[string]Handler //以bean名称为key
+	Container *core.Container
+	Optional  *RedisStore `name:"missing" autowired:"optional"`
+	Ignored   *Client     `autowired:"false"`
+	inited    bool
+	injected  bool //Init时字段是否已经注入
+}
+
+func (s *Service) Init(c *core.Container) {
+	s.inited = true
+	s.injected = s.Client != nil
+}
+
+func (s *Service) Inited() bool {
+	return s.inited
+}
+
+// InjectedBeforeInit Init时字段是否已经注入,容器先调用Init再注入字段
+func (s *Service) InjectedBeforeInit() bool {
+	return s.injected
+}
+
+// Register 注册示例bean
+func Register(app *ioc.Application) {
+	app.RegisterBeans(
+		core.NewBean(&Service{}),
+		core.NewBean(&MemoryStore{}).SetPrimary(true),
+		core.NewFactoryBean(NewRedisStore).SetName("redis"),
+		core.NewFactoryBean(NewClient),
+		core.NewFactoryBean(NewHelloHandler).SetName("hello").SetPriority(1),
+	)
+	app.RegisterSimpleBean(&ByeHandler{})
+}
+
+func NewRedisStore() *RedisStore {
+	return &RedisStore{Addr: "localhost:6379"}
+}
+
+func NewHelloHandler() HelloHandler {
+	return HelloHandler{}
+}
@@ -0,0 +1,66 @@
+// Code generated by go-spring gen. DO NOT EDIT.
+
+package example
+
+import (
+	"github.com/kgip/go-spring/core"
+)
+
+// GeneratedBeans go-spring gen静态装配的bean,按注册顺序排列
+type GeneratedBeans struct {
+	Service     *Service      //Service
+	MemoryStore *MemoryStore  //MemoryStore
+	Redis       *RedisStore   //redis
+	Client      *Client       //Client
+	Hello       *HelloHandler //hello
+	Bye         *ByeHandler   //bye
+}
+
+// WireBeans 按依赖顺序创建并装配bean,依赖关系与容器注入相同,不执行bean处理器;返回的清理方法按创建顺序的逆序调用工厂方法返回的清理方法
+func WireBeans(c *core.Container) (*GeneratedBeans, func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+	memoryStore := &MemoryStore{}
+	client, clientCleanup, err := NewClient(memoryStore)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if clientCleanup != nil {
+		cleanups = append(cleanups, clientCleanup)
+	}
+	redis := NewRedisStore()
+	bye := &ByeHandler{}
+	helloValue := NewHelloHandler()
+	hello := &helloValue
+	service := &Service{}
+	service.Init(c)
+	service.Client = client
+	service.Cache = redis
+	service.Handlers = wireOrderByPriority([]Handler{bye, hello}, core.GetPriority(bye), 1)
+	service.ByName = map[string]Handler{"bye": bye, "hello": hello}
+	service.Container = c
+	return &GeneratedBeans{
+		Service:     service,
+		MemoryStore: memoryStore,
+		Redis:       redis,
+		Client:      client,
+		Hello:       hello,
+		Bye:         bye,
+	}, cleanup, nil
+}
+
+// wireOrderByPriority 按优先级从高到低稳定排序,与容器切片注入的顺序相同
+func wireOrderByPriority[T any](items []T, priorities ...int) []T {
+	for i := 1; i < len(items); i++ {
+		for j := i; j > 0 && priorities[j] > priorities[j-1]; j-- {
+			items[j], items[j-1] = items[j-1], items[j]
+			priorities[j], priorities[j-1] = priorities[j-1], priorities[j]
+		}
+	}
+	return items
+}
@@ -0,0 +1,19 @@
+// Package unresolved 依赖无法解析的示例,go-spring gen生成的代码应当编译失败
+package unresolved
+
+import (
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+)
+
+type Repository interface {
+	Find(id int) string
+}
+
+type Service struct {
+	Repo Repository
+}
+
+func Register() {
+	ioc.RegisterBeans(core.NewBean(&Service{}))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"github.com/kgip/go-spring/cmd/go-spring/wire"
+	"github.com/kgip/go-spring/gen"
+	"os"
+)
+
+const usage = `usage: go-spring <command> [args...]
+
+commands:
+  proxy [dir...]       为注释中带有go-spring:proxy标记的接口生成代理,写入zz_generated_proxy.go
+  scan [dir...]        为嵌入core.Component的结构体和带有go-spring:bean标记的工厂函数生成注册代码,写入zz_generated_beans.go,dir/...递归扫描
+  gen [packages...]    为包及其导入的同一模块中的包注册的bean生成静态装配代码,写入zz_generated_wire.go,
+                       无法解析的依赖生成为未定义的标识符,编译时报错
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	args := os.Args[2:]
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	var err error
+	switch os.Args[1] {
+	case "proxy":
+		for _, dir := range args {
+			if err = gen.GenerateProxies(dir); err != nil {
+				break
+			}
+		}
+	case "scan":
+		err = gen.GenerateBeans(args...)
+	case "gen":
+		err = wire.GenerateWiring(args...)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
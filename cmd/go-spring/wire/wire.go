@@ -0,0 +1,1127 @@
+package wire
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kgip/go-spring/core"
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	WireFile             = "zz_generated_wire.go" //生成的装配文件名
+	corePackage          = "github.com/kgip/go-spring/core"
+	iocPackage           = "github.com/kgip/go-spring/ioc"
+	configurationPackage = "github.com/kgip/go-spring/configuration"
+)
+
+// wireBean 静态分析得到的bean注册
+type wireBean struct {
+	name            string
+	implicitName    bool //名称是否由类型名称推导
+	aliases         []string
+	qualifier       string
+	paramQualifiers map[int]string
+	primary         bool
+	priority        int
+	typ             types.Type   //实例类型,结构体bean为结构体指针
+	model           *types.Named //结构体bean的类型
+	factory         *types.Func  //工厂方法
+	valueResult     bool         //工厂方法返回结构体值
+	pos             token.Position
+	order           int
+	deps            []*wireDep
+	varName         string
+	fieldName       string
+}
+
+func (b *wireBean) elem() types.Type {
+	if ptr, ok := b.typ.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return b.typ
+}
+
+func (b *wireBean) hasName(name string) bool {
+	if b.name == name {
+		return true
+	}
+	for _, alias := range b.aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// wireDep 注入点及其静态解析结果
+type wireDep struct {
+	field      string //字段名,工厂方法参数为空
+	anonymous  bool
+	index      int
+	typ        types.Type
+	beanName   string
+	qualifier  string
+	optional   bool
+	container  bool //注入容器本身
+	collection bool
+	beans      []*wireBean
+	missing    string //依赖无法解析的原因,生成的代码引用未定义的标识符,编译时报错
+	unresolved string //依赖无法解析时引用的未定义标识符
+}
+
+func (d *wireDep) String() string {
+	if d.field != "" {
+		return "field " + d.field
+	}
+	return fmt.Sprintf("param %d", d.index)
+}
+
+// wirer 单个包的静态装配,bean从该包及其导入的同一模块中的包收集
+type wirer struct {
+	pkg      *packages.Package
+	scanning *packages.Package //正在收集bean的包
+	beans    []*wireBean
+	byName   map[string]*wireBean
+	errs     []string
+	imports  map[string]string //导入路径到包名
+	names    map[string]bool   //生成代码中已使用的标识符
+}
+
+// Wire 加载patterns对应的包,为包及其导入的同一模块中的包注册的bean生成静态装配代码,返回生成文件路径到源码的映射,
+// 没有注册bean的包和被其他加载的包导入的包不生成,其中的bean在导入它的包中装配
+func Wire(patterns ...string) (map[string][]byte, error) {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps | packages.NeedModule}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	imported := map[string]bool{}
+	for _, pkg := range pkgs {
+		for _, dep := range moduleImports(pkg) {
+			imported[dep.PkgPath] = true
+		}
+	}
+	sources := map[string][]byte{}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			//已生成的装配文件可能已经过期,忽略其中的错误
+			if !strings.Contains(e.Pos, WireFile) {
+				return nil, fmt.Errorf("load package %s: %v", pkg.PkgPath, e)
+			}
+		}
+		if len(pkg.GoFiles) == 0 || imported[pkg.PkgPath] {
+			continue
+		}
+		w := &wirer{pkg: pkg, byName: map[string]*wireBean{}, imports: map[string]string{}, names: map[string]bool{}}
+		source, err := w.generate()
+		if err != nil {
+			return nil, err
+		}
+		if source != nil {
+			sources[filepath.Join(filepath.Dir(pkg.GoFiles[0]), WireFile)] = source
+		}
+	}
+	return sources, nil
+}
+
+// GenerateWiring 为patterns对应的包生成静态装配代码,写入包目录下的zz_generated_wire.go
+func GenerateWiring(patterns ...string) error {
+	sources, err := Wire(patterns...)
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, len(sources))
+	for file := range sources {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if err := os.WriteFile(file, sources[file], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moduleImports 按导入顺序获取pkg直接或间接导入的同一模块中的包,被导入的包排在前面
+func moduleImports(pkg *packages.Package) []*packages.Package {
+	var imports []*packages.Package
+	visited := map[string]bool{pkg.PkgPath: true}
+	var visit func(pkg *packages.Package)
+	visit = func(pkg *packages.Package) {
+		paths := make([]string, 0, len(pkg.Imports))
+		for importPath := range pkg.Imports {
+			paths = append(paths, importPath)
+		}
+		sort.Strings(paths)
+		for _, importPath := range paths {
+			dep := pkg.Imports[importPath]
+			if visited[dep.PkgPath] || !sameModule(pkg, dep) {
+				continue
+			}
+			visited[dep.PkgPath] = true
+			visit(dep)
+			imports = append(imports, dep)
+		}
+	}
+	visit(pkg)
+	return imports
+}
+
+func sameModule(a, b *packages.Package) bool {
+	return a.Module != nil && b.Module != nil && a.Module.Path == b.Module.Path
+}
+
+func (w *wirer) generate() ([]byte, error) {
+	for _, pkg := range append(moduleImports(w.pkg), w.pkg) {
+		w.scanning = pkg
+		w.collect()
+	}
+	if len(w.errs) == 0 && len(w.beans) == 0 {
+		return nil, nil
+	}
+	if len(w.errs) == 0 {
+		w.name()
+	}
+	if len(w.errs) == 0 {
+		w.resolve()
+	}
+	var sorted []*wireBean
+	if len(w.errs) == 0 {
+		sorted = w.sort()
+	}
+	if len(w.errs) > 0 {
+		return nil, fmt.Errorf("wire package %s:\n\t%s", w.pkg.PkgPath, strings.Join(w.errs, "\n\t"))
+	}
+	return w.render(sorted)
+}
+
+func (w *wirer) errorf(pos token.Position, format string, args ...interface{}) {
+	w.errs = append(w.errs, fmt.Sprintf("%s: %s", pos, fmt.Sprintf(format, args...)))
+}
+
+func (w *wirer) position(node ast.Node) token.Position {
+	return w.pkg.Fset.Position(node.Pos())
+}
+
+// collect 收集正在扫描的包中core.NewBean、core.NewFactoryBean、RegisterSimpleBean和RegisterSimpleFactoryBean注册的bean
+func (w *wirer) collect() {
+	for _, file := range w.scanning.Syntax {
+		if filepath.Base(w.scanning.Fset.Position(file.Pos()).Filename) == WireFile {
+			continue
+		}
+		visited := map[*ast.CallExpr]bool{}
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || visited[call] {
+				return true
+			}
+			fn := w.callee(call)
+			switch {
+			case fn == nil:
+			case isBeanMethod(fn):
+				base, setters := w.chain(call)
+				for _, setter := range setters {
+					visited[setter] = true
+				}
+				if base == nil {
+					if isBeanSetter(fn.Name()) {
+						w.errorf(w.position(call), "%s must be chained directly on core.NewBean or core.NewFactoryBean for static wiring", fn.Name())
+					}
+					return true
+				}
+				visited[base] = true
+				w.addBean(base, setters)
+			case isPackageFunc(fn, corePackage, "NewBean", "NewFactoryBean"):
+				w.addBean(call, nil)
+			case isPackageFunc(fn, iocPackage, "RegisterSimpleBean", "RegisterSimpleFactoryBean"):
+				for _, arg := range call.Args {
+					w.addArg(arg, fn.Name() == "RegisterSimpleFactoryBean", nil)
+				}
+			}
+			return true
+		})
+	}
+}
+
+// callee 获取调用的函数或方法
+func (w *wirer) callee(call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := w.scanning.TypesInfo.Uses[ident].(*types.Func)
+	return fn
+}
+
+func isPackageFunc(fn *types.Func, pkgPath string, names ...string) bool {
+	if fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return false
+	}
+	for _, name := range names {
+		if fn.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isBeanMethod 是否为*core.Bean的方法
+func isBeanMethod(fn *types.Func) bool {
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	ptr, ok := recv.Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == corePackage && named.Obj().Name() == "Bean"
+}
+
+func isBeanSetter(name string) bool {
+	return strings.HasPrefix(name, "Set") || strings.HasPrefix(name, "Add")
+}
+
+// chain 解析链式调用,返回core.NewBean或core.NewFactoryBean调用及按调用顺序排列的setter
+func (w *wirer) chain(call *ast.CallExpr) (*ast.CallExpr, []*ast.CallExpr) {
+	var setters []*ast.CallExpr
+	for {
+		fn := w.callee(call)
+		if fn == nil {
+			return nil, setters
+		}
+		if isPackageFunc(fn, corePackage, "NewBean", "NewFactoryBean") {
+			for i, j := 0, len(setters)-1; i < j; i, j = i+1, j-1 {
+				setters[i], setters[j] = setters[j], setters[i]
+			}
+			return call, setters
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !isBeanMethod(fn) {
+			return nil, setters
+		}
+		setters = append(setters, call)
+		if call, ok = selector.X.(*ast.CallExpr); !ok {
+			return nil, setters
+		}
+	}
+}
+
+func (w *wirer) addBean(call *ast.CallExpr, setters []*ast.CallExpr) {
+	if len(call.Args) != 1 {
+		return
+	}
+	w.addArg(call.Args[0], w.callee(call).Name() == "NewFactoryBean", setters)
+}
+
+func (w *wirer) addArg(arg ast.Expr, factory bool, setters []*ast.CallExpr) {
+	bean := &wireBean{pos: w.position(arg), order: len(w.beans), paramQualifiers: map[int]string{}}
+	var ok bool
+	if factory {
+		ok = w.factoryOf(bean, arg)
+	} else {
+		ok = w.modelOf(bean, arg)
+	}
+	if !ok {
+		return
+	}
+	for _, setter := range setters {
+		w.applySetter(bean, setter)
+	}
+	if bean.implicitName && bean.model != nil && hasMethod(bean.typ, "GetBeanName") {
+		w.errorf(bean.pos, "bean name of %s comes from GetBeanName and can't be resolved statically, use SetName", bean.model)
+		return
+	}
+	w.beans = append(w.beans, bean)
+}
+
+// modelOf 解析结构体bean,参数为结构体或结构体指针
+func (w *wirer) modelOf(bean *wireBean, arg ast.Expr) bool {
+	named := namedStruct(w.scanning.TypesInfo.TypeOf(arg))
+	if named == nil {
+		w.errorf(bean.pos, "bean model must be a struct or struct pointer")
+		return false
+	}
+	if !named.Obj().Exported() && named.Obj().Pkg() != w.pkg.Types {
+		w.errorf(bean.pos, "unexported bean model %s of another package can't be wired statically", named)
+		return false
+	}
+	bean.model, bean.typ = named, types.NewPointer(named)
+	bean.name, bean.implicitName = named.Obj().Name(), true
+	return w.componentOf(bean)
+}
+
+// componentOf 应用嵌入的core.Component字段的bean标签,与core.NewBean相同
+func (w *wirer) componentOf(bean *wireBean) bool {
+	st := bean.model.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		if !st.Field(i).Anonymous() || !isComponent(st.Field(i).Type()) {
+			continue
+		}
+		options, err := core.ParseComponentTag(reflect.StructTag(st.Tag(i)).Get("bean"))
+		if err != nil {
+			w.errorf(bean.pos, "%v", err)
+			return false
+		}
+		if options.Prototype {
+			w.errorf(bean.pos, "prototype beans can't be wired statically")
+			return false
+		}
+		if options.Name != "" {
+			bean.name, bean.implicitName = options.Name, false
+		}
+	}
+	return true
+}
+
+// factoryOf 解析工厂方法bean,工厂方法必须是包级函数
+func (w *wirer) factoryOf(bean *wireBean, arg ast.Expr) bool {
+	var ident *ast.Ident
+	switch expr := arg.(type) {
+	case *ast.Ident:
+		ident = expr
+	case *ast.SelectorExpr:
+		ident = expr.Sel
+	}
+	var fn *types.Func
+	if ident != nil {
+		fn, _ = w.scanning.TypesInfo.Uses[ident].(*types.Func)
+	}
+	if fn == nil || fn.Type().(*types.Signature).Recv() != nil {
+		w.errorf(bean.pos, "factory method must be a package level function for static wiring")
+		return false
+	}
+	if !fn.Exported() && fn.Pkg() != w.pkg.Types {
+		w.errorf(bean.pos, "unexported factory method %s of another package can't be wired statically", fn.FullName())
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	results := sig.Results()
+	valid := results.Len() >= 1 && results.Len() <= 3
+	for i := 1; valid && i < results.Len(); i++ {
+		switch t := results.At(i).Type(); {
+		case isError(t):
+			valid = i == results.Len()-1
+		case isCleanup(t):
+			valid = i == 1
+		default:
+			valid = false
+		}
+	}
+	if !valid {
+		w.errorf(bean.pos, "factory method %s must return T, (T, error), (T, func()) or (T, func(), error)", fn.Name())
+		return false
+	}
+	bean.factory, bean.typ = fn, results.At(0).Type()
+	if named := namedStruct(bean.typ); named != nil {
+		if _, ok := bean.typ.(*types.Pointer); !ok {
+			bean.typ, bean.valueResult = types.NewPointer(named), true
+		}
+		bean.name = named.Obj().Name()
+	} else if named, ok := bean.typ.(*types.Named); ok {
+		bean.name = named.Obj().Name()
+	}
+	if bean.name == "" {
+		w.errorf(bean.pos, "bean name of factory method %s can't be derived from its result type, use SetName", fn.Name())
+		return false
+	}
+	bean.implicitName = true
+	return true
+}
+
+// applySetter 应用链式调用的setter,参数必须是常量
+func (w *wirer) applySetter(bean *wireBean, setter *ast.CallExpr) {
+	name := w.callee(setter).Name()
+	values := make([]constant.Value, len(setter.Args))
+	for i, arg := range setter.Args {
+		if values[i] = w.scanning.TypesInfo.Types[arg].Value; values[i] == nil {
+			w.errorf(w.position(arg), "argument of %s must be a constant for static wiring", name)
+			return
+		}
+	}
+	switch name {
+	case "SetName":
+		bean.name, bean.implicitName = constant.StringVal(values[0]), false
+	case "SetPrimary":
+		bean.primary = constant.BoolVal(values[0])
+	case "SetQualifier":
+		bean.qualifier = constant.StringVal(values[0])
+	case "SetParamQualifier":
+		index, _ := constant.Int64Val(values[0])
+		bean.paramQualifiers[int(index)] = constant.StringVal(values[1])
+	case "SetPriority":
+		priority, _ := constant.Int64Val(values[0])
+		bean.priority = int(priority)
+	case "AddAlias":
+		for _, value := range values {
+			bean.aliases = append(bean.aliases, constant.StringVal(value))
+		}
+	case "SetIsSingleton":
+		if !constant.BoolVal(values[0]) {
+			w.errorf(w.position(setter), "prototype beans can't be wired statically")
+		}
+	case "SetLazy":
+		//静态装配时所有bean都在WireBeans中创建,懒加载不影响装配结果
+	default:
+		w.errorf(w.position(setter), "%s isn't supported by static wiring", name)
+	}
+}
+
+// name 检查bean名称,与其他类型冲突的默认名称改用包路径限定的名称,与容器注册时的规则相同
+func (w *wirer) name() {
+	shortNames := map[string][]*wireBean{}
+	for _, bean := range w.beans {
+		if bean.implicitName {
+			shortNames[bean.name] = append(shortNames[bean.name], bean)
+		}
+	}
+	for _, beans := range shortNames {
+		for _, bean := range beans {
+			if types.Identical(bean.elem(), beans[0].elem()) {
+				continue
+			}
+			for _, bean := range beans {
+				if named, ok := bean.elem().(*types.Named); ok && named.Obj().Pkg() != nil {
+					bean.name = named.Obj().Pkg().Path() + "." + named.Obj().Name()
+				}
+			}
+			break
+		}
+	}
+	for _, bean := range w.beans {
+		for _, name := range append([]string{bean.name}, bean.aliases...) {
+			if existing := w.byName[name]; existing != nil {
+				w.errorf(bean.pos, "bean name %s is already registered at %s, static wiring doesn't support overrides", name, existing.pos)
+				continue
+			}
+			w.byName[name] = bean
+		}
+	}
+}
+
+// resolve 按容器的注入规则解析所有bean的依赖
+func (w *wirer) resolve() {
+	for _, bean := range w.beans {
+		if bean.model != nil {
+			w.fieldDeps(bean)
+		} else {
+			sig := bean.factory.Type().(*types.Signature)
+			for i := 0; i < sig.Params().Len(); i++ {
+				t := sig.Params().At(i).Type()
+				if sig.Variadic() && i == sig.Params().Len()-1 {
+					t = types.NewSlice(t.(*types.Slice).Elem())
+				}
+				bean.deps = append(bean.deps, &wireDep{index: i, typ: t, qualifier: bean.paramQualifiers[i]})
+			}
+		}
+		for _, dep := range bean.deps {
+			w.resolveDep(bean, dep)
+		}
+	}
+}
+
+// fieldDeps 获取结构体bean需要注入的字段,规则与容器的字段注入相同,配置字段需要容器加载配置,不支持静态装配
+func (w *wirer) fieldDeps(bean *wireBean) {
+	if w.implements(bean.typ, configurationPackage, "Storage") {
+		w.errorf(bean.pos, "configuration bean %s can't be wired statically", bean.name)
+		return
+	}
+	st := bean.model.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		f, tag := st.Field(i), reflect.StructTag(st.Tag(i))
+		inject, autowired := tag.Lookup("autowired")
+		beanName, named := tag.Lookup("name")
+		if autowired && inject == "false" || f.Anonymous() && isComponent(f.Type()) {
+			continue
+		}
+		if !f.Exported() && !autowired && !named {
+			continue
+		}
+		_, prefix := tag.Lookup("prefix")
+		_, key := tag.Lookup("key")
+		if prefix || key {
+			w.errorf(bean.pos, "configuration field %s.%s can't be wired statically", bean.name, f.Name())
+			continue
+		}
+		if !f.Exported() && f.Pkg() != w.pkg.Types {
+			w.errorf(bean.pos, "unexported field %s.%s of another package can't be wired statically", bean.name, f.Name())
+			continue
+		}
+		bean.deps = append(bean.deps, &wireDep{field: f.Name(), anonymous: f.Anonymous(), index: i, typ: f.Type(),
+			beanName: beanName, qualifier: tag.Get("qualifier"), optional: inject == "optional"})
+	}
+}
+
+// resolveDep 解析注入点,依赖不存在时生成的代码引用未定义的标识符,编译时报错,相当于容器的严格注入模式
+func (w *wirer) resolveDep(bean *wireBean, dep *wireDep) {
+	if dep.beanName == "" && isContainer(dep.typ) {
+		dep.container = true
+		return
+	}
+	if isLazy(dep.typ) {
+		w.errorf(bean.pos, "lazy dependency %s of bean %s can't be wired statically", dep, bean.name)
+		return
+	}
+	if elem := collectionElem(dep.typ); elem != nil && dep.beanName == "" {
+		dep.collection = true
+		for _, candidate := range w.candidates(elem) {
+			if dep.qualifier == "" || candidate.hasName(dep.qualifier) || candidate.qualifier == dep.qualifier {
+				dep.beans = append(dep.beans, candidate)
+			}
+		}
+		return
+	}
+	resolved, err := w.resolveBean(dep)
+	if err != "" {
+		w.errorf(bean.pos, "%s of bean %s: %s", dep, bean.name, err)
+		return
+	}
+	if resolved != nil {
+		dep.beans = []*wireBean{resolved}
+		return
+	}
+	if !dep.optional && isBeanType(dep.typ) && dep.missing == "" {
+		dep.missing = fmt.Sprintf("no bean of type %s", types.TypeString(dep.typ, nil))
+	}
+}
+
+// resolveBean 与容器相同依次按name标签、字段名、完整类型和候选bean查找依赖
+func (w *wirer) resolveBean(dep *wireDep) (*wireBean, string) {
+	if dep.beanName != "" {
+		bean := w.byName[dep.beanName]
+		if bean == nil {
+			if !dep.optional {
+				dep.missing = fmt.Sprintf("unknown bean name %s", dep.beanName)
+			}
+			return nil, ""
+		}
+		if !assignable(bean, dep.typ) {
+			return nil, fmt.Sprintf("bean %s can't be assigned to type %s", dep.beanName, types.TypeString(dep.typ, nil))
+		}
+		return bean, ""
+	}
+	if dep.qualifier == "" {
+		if dep.field != "" && !dep.anonymous {
+			if bean := w.byName[dep.field]; bean != nil && assignable(bean, dep.typ) {
+				return bean, ""
+			}
+		}
+		if named := namedStruct(dep.typ); named != nil {
+			for _, bean := range w.beans {
+				if types.Identical(bean.elem(), named) && (bean.implicitName || bean.name == named.Obj().Name()) {
+					return bean, ""
+				}
+			}
+		}
+	}
+	if !isBeanType(dep.typ) {
+		return nil, ""
+	}
+	candidates := w.candidates(dep.typ)
+	if dep.qualifier != "" {
+		var qualified []*wireBean
+		for _, candidate := range candidates {
+			if candidate.hasName(dep.qualifier) || candidate.qualifier == dep.qualifier {
+				qualified = append(qualified, candidate)
+			}
+		}
+		if len(qualified) == 0 && !dep.optional {
+			dep.missing = fmt.Sprintf("no bean of type %s qualified by %s", types.TypeString(dep.typ, nil), dep.qualifier)
+		}
+		candidates = qualified
+	}
+	if len(candidates) <= 1 {
+		if len(candidates) == 0 {
+			return nil, ""
+		}
+		return candidates[0], ""
+	}
+	var primary []*wireBean
+	for _, candidate := range candidates {
+		if candidate.primary {
+			primary = append(primary, candidate)
+		}
+	}
+	if len(primary) == 1 {
+		return primary[0], ""
+	}
+	names := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		names[i] = candidate.name
+	}
+	return nil, fmt.Sprintf("ambiguous beans of type %s: [%s]", types.TypeString(dep.typ, nil), strings.Join(names, ", "))
+}
+
+// candidates 可以赋值给t类型的bean,按名称排序
+func (w *wirer) candidates(t types.Type) []*wireBean {
+	var candidates []*wireBean
+	for _, bean := range w.beans {
+		if assignable(bean, t) {
+			candidates = append(candidates, bean)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].name < candidates[j].name
+	})
+	return candidates
+}
+
+// sort 按依赖关系拓扑排序,被依赖的bean排在前面,静态装配不支持循环依赖
+func (w *wirer) sort() []*wireBean {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*wireBean]int{}
+	var sorted []*wireBean
+	var path []string
+	var visit func(bean *wireBean) bool
+	visit = func(bean *wireBean) bool {
+		switch state[bean] {
+		case visiting:
+			w.errorf(bean.pos, "circular dependency can't be wired statically: %s -> %s", strings.Join(path, " -> "), bean.name)
+			return false
+		case visited:
+			return true
+		}
+		state[bean] = visiting
+		path = append(path, bean.name)
+		for _, dep := range bean.deps {
+			for _, to := range dep.beans {
+				if !visit(to) {
+					return false
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[bean] = visited
+		sorted = append(sorted, bean)
+		return true
+	}
+	beans := append([]*wireBean{}, w.beans...)
+	sort.SliceStable(beans, func(i, j int) bool {
+		return beans[i].name < beans[j].name
+	})
+	for _, bean := range beans {
+		if !visit(bean) {
+			return nil
+		}
+	}
+	return sorted
+}
+
+// render 生成装配代码
+func (w *wirer) render(sorted []*wireBean) ([]byte, error) {
+	for _, name := range []string{"c", "err", "cleanup", "cleanups", "GeneratedBeans", "WireBeans", "wireOrderByPriority"} {
+		w.names[name] = true
+	}
+	fields := map[string]bool{}
+	for _, bean := range w.beans {
+		bean.varName = w.identifier(lowerFirst(identifierOf(bean.name)), w.names)
+		bean.fieldName = w.identifier(upperFirst(identifierOf(bean.name)), fields)
+	}
+	var body bytes.Buffer
+	var needOrder bool
+	for _, bean := range sorted {
+		needOrder = w.renderBean(&body, bean) || needOrder
+	}
+	coreName := w.importName(corePackage, "core")
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by go-spring gen. DO NOT EDIT.\n\npackage %s\n\n", w.pkg.Name)
+	paths := make([]string, 0, len(w.imports))
+	for importPath := range w.imports {
+		paths = append(paths, importPath)
+	}
+	sort.Strings(paths)
+	buf.WriteString("import (\n")
+	for _, importPath := range paths {
+		if name := w.imports[importPath]; name == path.Base(importPath) {
+			fmt.Fprintf(&buf, "\t%q\n", importPath)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", name, importPath)
+		}
+	}
+	buf.WriteString(")\n\n// GeneratedBeans go-spring gen静态装配的bean,按注册顺序排列\ntype GeneratedBeans struct {\n")
+	for _, bean := range w.beans {
+		fmt.Fprintf(&buf, "\t%s %s //%s\n", bean.fieldName, w.typeString(bean.typ), bean.name)
+	}
+	buf.WriteString("}\n\n")
+	fmt.Fprintf(&buf, "// WireBeans 按依赖顺序创建并装配bean,依赖关系与容器注入相同,不执行bean处理器;返回的清理方法按创建顺序的逆序调用工厂方法返回的清理方法\n")
+	fmt.Fprintf(&buf, "func WireBeans(c *%s.Container) (*GeneratedBeans, func(), error) {\n", coreName)
+	buf.WriteString("\tvar cleanups []func()\n\tcleanup := func() {\n\t\tfor i := len(cleanups) - 1; i >= 0; i-- {\n\t\t\tcleanups[i]()\n\t\t}\n\t}\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("\treturn &GeneratedBeans{\n")
+	for _, bean := range w.beans {
+		fmt.Fprintf(&buf, "\t\t%s: %s,\n", bean.fieldName, bean.varName)
+	}
+	buf.WriteString("\t}, cleanup, nil\n}\n")
+	if needOrder {
+		buf.WriteString("\n// wireOrderByPriority 按优先级从高到低稳定排序,与容器切片注入的顺序相同\n")
+		buf.WriteString("func wireOrderByPriority[T any](items []T, priorities ...int) []T {\n")
+		buf.WriteString("\tfor i := 1; i < len(items); i++ {\n\t\tfor j := i; j > 0 && priorities[j] > priorities[j-1]; j-- {\n")
+		buf.WriteString("\t\t\titems[j], items[j-1] = items[j-1], items[j]\n\t\t\tpriorities[j], priorities[j-1] = priorities[j-1], priorities[j]\n\t\t}\n\t}\n\treturn items\n}\n")
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated wiring: %w", err)
+	}
+	return source, nil
+}
+
+// renderBean 生成单个bean的创建、初始化和注入代码,与容器相同先调用Init再注入字段,返回是否需要运行时按优先级排序
+func (w *wirer) renderBean(buf *bytes.Buffer, bean *wireBean) bool {
+	var needOrder bool
+	for _, dep := range bean.deps {
+		if dep.missing == "" {
+			continue
+		}
+		name := dep.field
+		if name == "" {
+			name = fmt.Sprintf("param%d", dep.index)
+		}
+		dep.unresolved = w.identifier("unresolved"+upperFirst(identifierOf(bean.name))+upperFirst(name), w.names)
+		fmt.Fprintf(buf, "\t// %s 未定义,编译时报错: bean %s的依赖%s无法解析,%s\n", dep.unresolved, bean.name, dep, dep.missing)
+	}
+	if bean.model != nil {
+		fmt.Fprintf(buf, "\t%s := &%s{}\n", bean.varName, w.typeString(bean.model))
+		w.renderInit(buf, bean)
+		for _, dep := range bean.deps {
+			value, order := w.value(dep)
+			needOrder = needOrder || order
+			if value != "" {
+				fmt.Fprintf(buf, "\t%s.%s = %s\n", bean.varName, dep.field, value)
+			}
+		}
+		return needOrder
+	}
+	sig := bean.factory.Type().(*types.Signature)
+	args := make([]string, len(bean.deps))
+	for i, dep := range bean.deps {
+		value, order := w.value(dep)
+		needOrder = needOrder || order
+		if value == "" {
+			value = w.zero(dep.typ)
+		}
+		args[i] = value
+	}
+	if sig.Variadic() && len(args) > 0 {
+		args[len(args)-1] += "..."
+	}
+	results := []string{bean.varName}
+	if bean.valueResult {
+		results[0] += "Value"
+	}
+	hasCleanup := sig.Results().Len() > 1 && isCleanup(sig.Results().At(1).Type())
+	if hasCleanup {
+		results = append(results, bean.varName+"Cleanup")
+	}
+	hasErr := isError(sig.Results().At(sig.Results().Len() - 1).Type())
+	if hasErr {
+		results = append(results, "err")
+	}
+	fmt.Fprintf(buf, "\t%s := %s(%s)\n", strings.Join(results, ", "), w.funcString(bean.factory), strings.Join(args, ", "))
+	if hasErr {
+		buf.WriteString("\tif err != nil {\n\t\tcleanup()\n\t\treturn nil, nil, err\n\t}\n")
+	}
+	if hasCleanup {
+		fmt.Fprintf(buf, "\tif %sCleanup != nil {\n\t\tcleanups = append(cleanups, %sCleanup)\n\t}\n", bean.varName, bean.varName)
+	}
+	if bean.valueResult {
+		fmt.Fprintf(buf, "\t%s := &%sValue\n", bean.varName, bean.varName)
+	}
+	w.renderInit(buf, bean)
+	return needOrder
+}
+
+// renderInit 生成调用初始化方法的代码
+func (w *wirer) renderInit(buf *bytes.Buffer, bean *wireBean) {
+	if w.implements(bean.typ, corePackage, "Initializer") {
+		fmt.Fprintf(buf, "\t%s.Init(c)\n", bean.varName)
+	}
+}
+
+// value 注入点的值表达式,没有依赖时返回空字符串,依赖无法解析时返回未定义的标识符
+func (w *wirer) value(dep *wireDep) (string, bool) {
+	if dep.container {
+		return "c", false
+	}
+	if dep.unresolved != "" {
+		return dep.unresolved, false
+	}
+	if !dep.collection {
+		if len(dep.beans) == 0 {
+			return "", false
+		}
+		return w.convert(dep.beans[0], dep.typ), false
+	}
+	elem := collectionElem(dep.typ)
+	if _, ok := dep.typ.Underlying().(*types.Map); ok {
+		items := make([]string, len(dep.beans))
+		for i, bean := range dep.beans {
+			items[i] = fmt.Sprintf("%q: %s", bean.name, w.convert(bean, elem))
+		}
+		return fmt.Sprintf("%s{%s}", w.typeString(dep.typ), strings.Join(items, ", ")), false
+	}
+	beans := append([]*wireBean{}, dep.beans...)
+	dynamic := false
+	for _, bean := range beans {
+		dynamic = dynamic || bean.priority == 0 && hasMethod(bean.typ, "GetPriority")
+	}
+	items := make([]string, len(beans))
+	if !dynamic {
+		sort.SliceStable(beans, func(i, j int) bool {
+			return beans[i].priority > beans[j].priority
+		})
+		for i, bean := range beans {
+			items[i] = w.convert(bean, elem)
+		}
+		return fmt.Sprintf("%s{%s}", w.typeString(dep.typ), strings.Join(items, ", ")), false
+	}
+	priorities := make([]string, len(beans))
+	for i, bean := range beans {
+		items[i] = w.convert(bean, elem)
+		if priorities[i] = strconv.Itoa(bean.priority); bean.priority == 0 {
+			priorities[i] = fmt.Sprintf("%s.GetPriority(%s)", w.importName(corePackage, "core"), bean.varName)
+		}
+	}
+	return fmt.Sprintf("wireOrderByPriority(%s{%s}, %s)", w.typeString(dep.typ), strings.Join(items, ", "), strings.Join(priorities, ", ")), true
+}
+
+// convert bean实例转换为t类型,t为结构体时取指针指向的值
+func (w *wirer) convert(bean *wireBean, t types.Type) string {
+	if !types.AssignableTo(bean.typ, t) {
+		return "*" + bean.varName
+	}
+	return bean.varName
+}
+
+func (w *wirer) zero(t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+	case *types.Struct, *types.Array:
+		return w.typeString(t) + "{}"
+	}
+	return "nil"
+}
+
+func (w *wirer) typeString(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == w.pkg.Types {
+			return ""
+		}
+		return w.importName(pkg.Path(), pkg.Name())
+	})
+}
+
+func (w *wirer) funcString(fn *types.Func) string {
+	if fn.Pkg() == w.pkg.Types {
+		return fn.Name()
+	}
+	return w.importName(fn.Pkg().Path(), fn.Pkg().Name()) + "." + fn.Name()
+}
+
+// importName 获取导入包在生成代码中的名称,与已使用的标识符冲突时添加数字后缀
+func (w *wirer) importName(importPath string, name string) string {
+	if existing, ok := w.imports[importPath]; ok {
+		return existing
+	}
+	name = w.identifier(name, w.names)
+	w.imports[importPath] = name
+	return name
+}
+
+// identifier 生成未使用的标识符
+func (w *wirer) identifier(name string, used map[string]bool) string {
+	if token.Lookup(name).IsKeyword() {
+		name += "Bean"
+	}
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// identifierOf bean名称转换为标识符,包路径限定的名称只保留包名和类型名
+func identifierOf(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	var builder strings.Builder
+	upper := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			upper = true
+			continue
+		}
+		if upper && builder.Len() > 0 {
+			r = unicode.ToUpper(r)
+		}
+		upper = false
+		builder.WriteRune(r)
+	}
+	if builder.Len() == 0 || unicode.IsDigit([]rune(builder.String())[0]) {
+		return "bean" + builder.String()
+	}
+	return builder.String()
+}
+
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func upperFirst(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// namedStruct t为命名结构体或其指针时返回结构体类型
+func namedStruct(t types.Type) *types.Named {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil
+	}
+	return named
+}
+
+// assignable bean实例能否赋值给t类型,与容器相同允许结构体指针赋值给结构体
+func assignable(bean *wireBean, t types.Type) bool {
+	return types.AssignableTo(bean.typ, t) || types.AssignableTo(bean.elem(), t) && namedStruct(bean.typ) != nil
+}
+
+// isBeanType 与容器相同,非空接口、结构体和结构体指针视为bean类型
+func isBeanType(t types.Type) bool {
+	if iface, ok := t.Underlying().(*types.Interface); ok {
+		return iface.NumMethods() > 0
+	}
+	return namedStruct(t) != nil
+}
+
+// collectionElem 切片和以字符串为key的map的元素类型,元素不是bean类型时返回nil
+func collectionElem(t types.Type) types.Type {
+	var elem types.Type
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		elem = u.Elem()
+	case *types.Map:
+		if basic, ok := u.Key().Underlying().(*types.Basic); !ok || basic.Info()&types.IsString == 0 {
+			return nil
+		}
+		elem = u.Elem()
+	}
+	if elem == nil || !isBeanType(elem) {
+		return nil
+	}
+	return elem
+}
+
+// isLazy 是否为延迟注入点,func() T、func() (T, error)和core.Provider[T]
+func isLazy(t types.Type) bool {
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == corePackage && named.Obj().Name() == "Provider" {
+		return true
+	}
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok || sig.Params().Len() != 0 || sig.Results().Len() == 0 || sig.Results().Len() > 2 {
+		return false
+	}
+	return (sig.Results().Len() == 1 || isError(sig.Results().At(1).Type())) && isBeanType(sig.Results().At(0).Type())
+}
+
+// isContainer 是否为容器指针或core.EventPublisher
+func isContainer(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == corePackage &&
+		(named.Obj().Name() == "Container" || named.Obj().Name() == "EventPublisher")
+}
+
+// isComponent 是否为core.Component标记
+func isComponent(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == corePackage && named.Obj().Name() == "Component"
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+func isCleanup(t types.Type) bool {
+	sig, ok := t.(*types.Signature)
+	return ok && sig.Params().Len() == 0 && sig.Results().Len() == 0
+}
+
+// hasMethod t的方法集中是否包含名为name的方法
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// implements t是否实现了pkgPath包中名为name的接口,接口从当前包导入的包中查找
+func (w *wirer) implements(t types.Type, pkgPath string, name string) bool {
+	seen := map[*types.Package]bool{}
+	var lookup func(pkg *types.Package) *types.Interface
+	lookup = func(pkg *types.Package) *types.Interface {
+		if seen[pkg] {
+			return nil
+		}
+		seen[pkg] = true
+		if pkg.Path() == pkgPath {
+			if obj := pkg.Scope().Lookup(name); obj != nil {
+				iface, _ := obj.Type().Underlying().(*types.Interface)
+				return iface
+			}
+			return nil
+		}
+		for _, imported := range pkg.Imports() {
+			if iface := lookup(imported); iface != nil {
+				return iface
+			}
+		}
+		return nil
+	}
+	iface := lookup(w.pkg.Types)
+	return iface != nil && types.Implements(t, iface)
+}
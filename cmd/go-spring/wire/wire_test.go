@@ -0,0 +1,136 @@
+package wire
+
+import (
+	"bytes"
+	"context"
+	"github.com/kgip/go-spring/cmd/go-spring/internal/crosspkg/service"
+	"github.com/kgip/go-spring/cmd/go-spring/internal/example"
+	"github.com/kgip/go-spring/ioc"
+	"golang.org/x/tools/go/packages"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type emptyConfiguration struct{}
+
+func (emptyConfiguration) Load() {}
+
+func (emptyConfiguration) GetConfig(configKey string) interface{} {
+	return nil
+}
+
+func TestGeneratedWiringUpToDate(t *testing.T) {
+	sources, err := Wire("../internal/example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, _ := filepath.Abs(filepath.Join("../internal/example", WireFile))
+	generated, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sources[file], generated) {
+		t.Fatal("generated wiring is out of date, run go-spring gen ./internal/example")
+	}
+}
+
+func TestUnresolvedDependency(t *testing.T) {
+	sources, err := Wire("../internal/unresolved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, _ := filepath.Abs(filepath.Join("../internal/unresolved", WireFile))
+	if !bytes.Contains(sources[file], []byte("service.Repo = unresolvedServiceRepo")) {
+		t.Fatalf("unresolved dependency should be wired to an undefined identifier, got\n%s", sources[file])
+	}
+	cfg := &packages.Config{Mode: packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo, Overlay: map[string][]byte{file: sources[file]}}
+	pkgs, err := packages.Load(cfg, "../internal/unresolved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []string
+	for _, e := range pkgs[0].Errors {
+		errs = append(errs, e.Msg)
+	}
+	if !strings.Contains(strings.Join(errs, "\n"), "undefined: unresolvedServiceRepo") {
+		t.Fatalf("generated wiring should fail to compile, got %v", errs)
+	}
+}
+
+func TestCrossPackageWiring(t *testing.T) {
+	sources, err := Wire("../internal/crosspkg/...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, _ := filepath.Abs(filepath.Join("../internal/crosspkg/service", WireFile))
+	if len(sources) != 1 || sources[file] == nil {
+		t.Fatalf("beans of imported packages should be wired in the importing package only, got %d files", len(sources))
+	}
+	generated, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sources[file], generated) {
+		t.Fatal("generated wiring is out of date, run go-spring gen ./internal/crosspkg/...")
+	}
+	app := ioc.NewApplication(ioc.WithConfiguration(emptyConfiguration{}), ioc.WithLogger(log.New(io.Discard, "", 0)))
+	service.Register(app)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	beans, _, err := service.WireBeans(app.Container())
+	if err != nil {
+		t.Fatal(err)
+	}
+	injected := app.Container().GetBeanInstanceByName("Service").(*service.Service)
+	if beans.Service.Repo != beans.MemoryRepository || beans.Service.Repo.Find(1) != injected.Repo.Find(1) {
+		t.Fatal("dependency registered in another package should be wired")
+	}
+}
+
+func TestWiringMatchesContainer(t *testing.T) {
+	app := ioc.NewApplication(ioc.WithConfiguration(emptyConfiguration{}), ioc.WithLogger(log.New(io.Discard, "", 0)))
+	example.Register(app)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	injected := app.Container().GetBeanInstanceByName("Service").(*example.Service)
+	beans, cleanup, err := example.WireBeans(app.Container())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wired := beans.Service
+	if wired.Client.Store != example.Store(beans.MemoryStore) || wired.Cache != example.Store(beans.Redis) {
+		t.Fatal("primary and qualified stores should be wired")
+	}
+	if handles(injected) != handles(wired) || handles(wired) != "bye,hello" {
+		t.Fatalf("handlers should be ordered like the container, got %s and %s", handles(injected), handles(wired))
+	}
+	if len(wired.ByName) != len(injected.ByName) || wired.ByName["hello"] == nil || wired.ByName["bye"] == nil {
+		t.Fatal("map injection should be keyed by bean name")
+	}
+	if wired.InjectedBeforeInit() != injected.InjectedBeforeInit() || wired.InjectedBeforeInit() {
+		t.Fatal("Init should be called before field injection like the container")
+	}
+	if !wired.Inited() || wired.Container != app.Container() || wired.Optional != nil || wired.Ignored != nil {
+		t.Fatalf("wired service should match the container rules, got %+v", wired)
+	}
+	closed := example.Closed
+	cleanup()
+	if example.Closed != closed+1 {
+		t.Fatal("cleanup should call factory cleanups")
+	}
+	_ = app.Stop(context.Background())
+}
+
+func handles(service *example.Service) string {
+	names := make([]string, len(service.Handlers))
+	for i, handler := range service.Handlers {
+		names[i] = handler.Handle()
+	}
+	return strings.Join(names, ",")
+}
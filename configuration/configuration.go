@@ -1,10 +1,20 @@
 package configuration
 
 import (
+	"fmt"
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+)
+
+const (
+	ProfilesKey  = "profiles.active"           //配置文件中激活profile的配置项
+	ProfilesEnv  = "GO_SPRING_PROFILES_ACTIVE" //激活profile的环境变量
+	ProfilesFlag = "--profiles.active"         //激活profile的命令行参数,如--profiles.active=dev,test
 )
 
 type Configuration struct {
@@ -12,12 +22,16 @@ type Configuration struct {
 	path       string
 	refresh    bool //是否刷新配置
 	configType string
-	viper      *viper.Viper
+	defaults   map[string]interface{} //配置项的默认值
 	logger     *log.Logger
+	profiles   []string          //激活的profile
+	callbacks  []func()          //配置变化的回调
+	watcher    *fsnotify.Watcher //开启刷新时的配置文件监听器
+	lock       sync.RWMutex
 }
 
 func NewConfiguration(path string, configType string, refresh bool, logger *log.Logger) *Configuration {
-	return &Configuration{path: path, refresh: refresh, configType: configType, logger: logger, viper: viper.New()}
+	return &Configuration{path: path, refresh: refresh, configType: configType, logger: logger, defaults: map[string]interface{}{}}
 }
 
 func (c *Configuration) SetPath(path string) {
@@ -32,41 +46,206 @@ func (c *Configuration) SetRefresh(refresh bool) {
 	c.refresh = refresh
 }
 
+// SetDefault 设置配置项的默认值,需要在Load之前调用
+func (c *Configuration) SetDefault(configKey string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.defaults[configKey] = value
+}
+
+// SetActiveProfiles 设置激活的profile,优先级高于命令行参数、环境变量和配置文件
+func (c *Configuration) SetActiveProfiles(profiles ...string) {
+	c.profiles = profiles
+}
+
+// OnChange 注册配置变化的回调,配置文件变化并重新加载后调用
+func (c *Configuration) OnChange(callback func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.callbacks = append(c.callbacks, callback)
+}
+
+// ActiveProfiles 获取激活的profile,加载配置后才包含从命令行参数、环境变量和配置文件中读取的profile
+func (c *Configuration) ActiveProfiles() []string {
+	return c.profiles
+}
+
+// Load 加载配置文件,再依次合并激活的profile对应的配置文件,如config.yaml激活dev时合并config-dev.yaml,
+// 开启刷新时监听配置文件和profile配置文件,任一文件变化后重新加载并合并
 func (c *Configuration) Load() {
 	c.logger.Printf("load configuration from path %s", c.path)
-	c.viper.SetConfigFile(c.path)
-	c.viper.SetConfigType(c.configType)
-	if err := viper.ReadInConfig(); err != nil {
+	if err := c.read(); err != nil {
 		panic(err)
 	}
 	if c.refresh {
-		c.viper.OnConfigChange(func(e fsnotify.Event) {
-			c.logger.Println("config file changed")
-			c.configs = viper.AllSettings()
-		})
+		c.watch()
+	}
+	c.logger.Printf("initialize config complete, active profiles: %v", c.profiles)
+}
+
+// read 读取配置文件并合并激活的profile对应的配置文件,首次读取时确定激活的profile,
+// 每次读取都使用新的viper,全部合并完成后再替换配置,读取过程中获取的仍是上一次的配置
+func (c *Configuration) read() error {
+	v := viper.New()
+	v.SetConfigType(c.configType)
+	c.lock.RLock()
+	for key, value := range c.defaults {
+		v.SetDefault(key, value)
+	}
+	c.lock.RUnlock()
+	v.SetConfigFile(c.path)
+	if err := v.ReadInConfig(); err != nil {
+		return err
 	}
-	c.configs = viper.AllSettings()
-	c.logger.Println("initialize config complete")
+	if len(c.profiles) == 0 {
+		c.profiles = resolveProfiles(v)
+	}
+	for _, profile := range c.profiles {
+		path := c.profilePath(profile)
+		if _, err := os.Stat(path); err != nil {
+			c.logger.Printf("configuration of profile %s not found at path %s", profile, path)
+			continue
+		}
+		c.logger.Printf("merge configuration of profile %s from path %s", profile, path)
+		v.SetConfigFile(path)
+		if err := v.MergeInConfig(); err != nil {
+			return err
+		}
+	}
+	configs := v.AllSettings()
+	c.lock.Lock()
+	c.configs = configs
+	c.lock.Unlock()
+	return nil
+}
+
+// watch 监听配置文件和所有激活的profile配置文件所在的目录,profile配置文件在加载后才创建时同样会被合并
+func (c *Configuration) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		c.logger.Printf("watch configuration failed: %v", err)
+		return
+	}
+	files := map[string]bool{}
+	dirs := map[string]bool{}
+	paths := []string{c.path}
+	for _, profile := range c.profiles {
+		paths = append(paths, c.profilePath(profile))
+	}
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			files[abs] = true
+			dirs[filepath.Dir(abs)] = true
+		}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			c.logger.Printf("watch configuration directory %s failed: %v", dir, err)
+		}
+	}
+	c.lock.Lock()
+	c.watcher = watcher
+	c.lock.Unlock()
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				abs, _ := filepath.Abs(event.Name)
+				if !files[abs] || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				c.logger.Printf("config file %s changed", event.Name)
+				if err := c.read(); err != nil {
+					c.logger.Printf("reload configuration failed: %v", err)
+					continue
+				}
+				c.lock.RLock()
+				callbacks := c.callbacks
+				c.lock.RUnlock()
+				for _, callback := range callbacks {
+					callback()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				c.logger.Printf("watch configuration failed: %v", err)
+			}
+		}
+	}()
+}
+
+// Close 停止监听配置文件,容器关闭时调用
+func (c *Configuration) Close() error {
+	c.lock.Lock()
+	watcher := c.watcher
+	c.watcher = nil
+	c.lock.Unlock()
+	if watcher == nil {
+		return nil
+	}
+	return watcher.Close()
+}
+
+// resolveProfiles 依次从命令行参数、环境变量和配置文件中读取激活的profile
+func resolveProfiles(v *viper.Viper) []string {
+	for i, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, ProfilesFlag+"=") {
+			return splitProfiles(strings.TrimPrefix(arg, ProfilesFlag+"="))
+		}
+		if arg == ProfilesFlag && i+2 < len(os.Args) {
+			return splitProfiles(os.Args[i+2])
+		}
+	}
+	if env, ok := os.LookupEnv(ProfilesEnv); ok {
+		return splitProfiles(env)
+	}
+	switch profiles := v.Get(ProfilesKey).(type) {
+	case string:
+		return splitProfiles(profiles)
+	case []interface{}:
+		var result []string
+		for _, profile := range profiles {
+			result = append(result, splitProfiles(fmt.Sprint(profile))...)
+		}
+		return result
+	}
+	return nil
+}
+
+func (c *Configuration) profilePath(profile string) string {
+	ext := filepath.Ext(c.path)
+	return strings.TrimSuffix(c.path, ext) + "-" + profile + ext
+}
+
+func splitProfiles(profiles string) []string {
+	var result []string
+	for _, profile := range strings.Split(profiles, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			result = append(result, profile)
+		}
+	}
+	return result
 }
 
 func (c *Configuration) GetConfig(configKey string) interface{} {
 	splitedKeys := strings.Split(configKey, ".")
+	c.lock.RLock()
 	configMap := c.configs
+	c.lock.RUnlock()
 	for i := 0; i < len(splitedKeys); i++ {
-		isMatched := false
-		for mapKey, value := range configMap {
-			if mapKey == splitedKeys[i] {
-				if i >= len(splitedKeys) {
-					return value
-				} else {
-					isMatched = true
-					configMap = value.(map[string]interface{})
-					break
-				}
-			}
+		value, ok := configMap[strings.ToLower(splitedKeys[i])]
+		if !ok {
+			return nil
+		}
+		if i == len(splitedKeys)-1 {
+			return value
 		}
-		if !isMatched {
-			break
+		if configMap, ok = value.(map[string]interface{}); !ok {
+			return nil
 		}
 	}
 	return nil
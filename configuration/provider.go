@@ -5,6 +5,21 @@ type Provider interface {
 	GetConfig(configKey string) interface{}
 }
 
+// ProfileProvider 支持profile的配置提供者,返回加载配置后激活的profile
+type ProfileProvider interface {
+	ActiveProfiles() []string
+}
+
+// DefaultsProvider 支持默认值的配置提供者,配置文件中不存在的配置项返回默认值
+type DefaultsProvider interface {
+	SetDefault(configKey string, value interface{})
+}
+
+// ChangeNotifier 支持变更通知的配置提供者,配置发生变化并重新加载后调用注册的回调
+type ChangeNotifier interface {
+	OnChange(callback func())
+}
+
 // Storage 实现该接口的类被视为配置类
 type Storage interface {
 	ConfigurationPrefix() string
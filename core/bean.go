@@ -0,0 +1,361 @@
+package core
+
+import (
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Bean 表示一个对象
+type Bean struct {
+	name               string
+	implicitName       bool //名称是否由类型名称推导,与其他类型冲突时改用包路径限定的名称
+	priority           int
+	model              interface{}       //原始对象,struct指针
+	instance           interface{}       //创建完成后并赋值后的实例指针
+	factoryMethod      interface{}       //实例化工厂方法
+	cleanup            func()            //工厂方法返回的清理方法,容器关闭时调用
+	isSingleton        bool              //是否单例
+	lazy               bool              //懒加载的单例bean在第一次被获取或被依赖时才创建
+	isPrimary          bool              //存在多个候选bean时优先注入
+	qualifier          string            //限定符,与qualifier标签匹配
+	injectionPoints    []*injectionPoint //注册时解析出的注入点
+	conditions         []Condition       //注册条件,全部满足时bean才会被实例化
+	order              int               //注册顺序
+	aliases            []string          //别名,可以代替名称查找bean
+	source             string            //注册位置,file:line
+	beanPreProcessors  []BeanPreProcessor
+	beanPostProcessors []BeanPostProcessor
+	lock               *sync.Mutex
+}
+
+func NewBean(model interface{}) *Bean {
+	bean, err := NewBeanE(model)
+	if err != nil {
+		panic(err)
+	}
+	return bean
+}
+
+// NewBeanE 通过结构体或结构体指针创建bean,默认以结构体名称作为bean名称
+func NewBeanE(model interface{}) (*Bean, error) {
+	bean := &Bean{lock: &sync.Mutex{}, isSingleton: true}
+	if err := bean.SetModelE(model); err != nil {
+		return nil, err
+	}
+	if provider, ok := bean.model.(BeanNameProvider); ok {
+		bean.name = provider.GetBeanName()
+	}
+	if bean.name == "" {
+		bean.name = reflect.TypeOf(bean.model).Elem().Name()
+		bean.implicitName = true
+	}
+	//嵌入Component时使用bean标签中的选项
+	options, err := componentOptionsOf(reflect.TypeOf(bean.model))
+	if err != nil {
+		return nil, err
+	}
+	if options != nil {
+		options.apply(bean)
+	}
+	if bean.name == "" {
+		return nil, errors.NameEmptyError
+	}
+	return bean, nil
+}
+
+func NewFactoryBean(factoryMethod interface{}) *Bean {
+	bean, err := NewFactoryBeanE(factoryMethod)
+	if err != nil {
+		panic(err)
+	}
+	return bean
+}
+
+// NewFactoryBeanE 通过工厂方法创建bean
+func NewFactoryBeanE(factoryMethod interface{}) (*Bean, error) {
+	bean := &Bean{lock: &sync.Mutex{}, isSingleton: true}
+	if err := bean.SetFactoryMethodE(factoryMethod); err != nil {
+		return nil, err
+	}
+	if provider, ok := bean.factoryMethod.(BeanNameProvider); ok {
+		bean.name = provider.GetBeanName()
+	}
+	//默认以工厂方法返回值的类型名称作为bean名称
+	if bean.name == "" {
+		rt := reflect.TypeOf(bean.factoryMethod).Out(0)
+		if bean.name = structBeanName(rt); bean.name == "" {
+			bean.name = rt.Name()
+		}
+		bean.implicitName = rt.Kind() == reflect.Ptr && bean.name == rt.Elem().Name() || bean.name == rt.Name()
+	}
+	if bean.name == "" {
+		return nil, errors.NameEmptyError
+	}
+	return bean, nil
+}
+
+func (bean *Bean) SetName(name string) *Bean {
+	if name != "" {
+		bean.name = name
+		bean.implicitName = false
+	} else {
+		panic(errors.NameEmptyError)
+	}
+	return bean
+}
+
+func (bean *Bean) SetPriority(priority int) *Bean {
+	bean.priority = priority
+	return bean
+}
+
+func (bean *Bean) SetModel(model interface{}) *Bean {
+	if err := bean.SetModelE(model); err != nil {
+		panic(err)
+	}
+	return bean
+}
+
+// SetModelE 设置原始对象,只接收结构体或结构体指针,结构体会被复制为指针保存
+func (bean *Bean) SetModelE(model interface{}) error {
+	if model == nil {
+		return errors.NilError
+	}
+	rt := reflect.TypeOf(model)
+	if rt.Kind() == reflect.Ptr && rt.Elem().Kind() != reflect.Struct || rt.Kind() != reflect.Ptr && rt.Kind() != reflect.Struct {
+		return errors.TypeNotMatchError.Detail(fmt.Sprintf("model must be a struct or struct pointer, got %s", rt))
+	}
+	if rt.Kind() == reflect.Struct {
+		ptr := reflect.New(rt)
+		ptr.Elem().Set(reflect.ValueOf(model))
+		bean.model = ptr.Interface()
+	} else {
+		bean.model = model
+	}
+	bean.injectionPoints = fieldInjectionPoints(reflect.TypeOf(bean.model))
+	return nil
+}
+
+func (bean *Bean) SetFactoryMethod(method interface{}) *Bean {
+	if err := bean.SetFactoryMethodE(method); err != nil {
+		panic(err)
+	}
+	return bean
+}
+
+// SetFactoryMethodE 设置实例化工厂方法,参数均从容器中注入(可变参数注入所有匹配的bean),
+// 返回值为(T)、(T, error)、(T, func())或(T, func(), error),T为结构体、结构体指针或接口,func()为容器关闭时调用的清理方法,
+// 工厂方法返回error时容器立即调用已返回的清理方法,原型bean不能使用返回清理方法的工厂方法
+func (bean *Bean) SetFactoryMethodE(method interface{}) error {
+	if method == nil {
+		return errors.NilError
+	}
+	rt := reflect.TypeOf(method)
+	if rt.Kind() != reflect.Func {
+		return errors.TypeNotMatchError.Detail(fmt.Sprintf("factory method must be a func, got %s", rt))
+	}
+	if _, _, ok := factoryReturns(rt); !ok {
+		return errors.FactoryMethodReturnsError.Detail(fmt.Sprintf("factory method %s must return (T), (T, error), (T, func()) or (T, func(), error)", rt))
+	}
+	returnRt := rt.Out(0)
+	if returnRt.Kind() == reflect.Ptr && returnRt.Elem().Kind() != reflect.Struct ||
+		returnRt.Kind() != reflect.Ptr && returnRt.Kind() != reflect.Struct && returnRt.Kind() != reflect.Interface {
+		return errors.TypeNotMatchError.Detail(fmt.Sprintf("factory method type %s must be a struct, struct pointer or interface", returnRt))
+	}
+	bean.factoryMethod = method
+	bean.injectionPoints = append(paramInjectionPoints(rt), fieldInjectionPoints(bean.instanceType())...)
+	return nil
+}
+
+func (bean *Bean) scope() string {
+	if bean.isSingleton {
+		return singletonScope
+	}
+	return prototypeScope
+}
+
+func sortByRegistration(beans []*Bean) {
+	sort.SliceStable(beans, func(i, j int) bool {
+		return beans[i].order < beans[j].order
+	})
+}
+
+// factoryReturns 解析工厂方法的返回值,返回清理方法和error的下标,不存在时为-1
+func factoryReturns(rt reflect.Type) (cleanupIndex, errIndex int, ok bool) {
+	cleanupIndex, errIndex = -1, -1
+	if rt.NumOut() == 0 || rt.NumOut() > 3 {
+		return cleanupIndex, errIndex, false
+	}
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	cleanupType := reflect.TypeOf(func() {})
+	for i := 1; i < rt.NumOut(); i++ {
+		switch {
+		case rt.Out(i) == cleanupType && i == 1:
+			cleanupIndex = i
+		case rt.Out(i) == errorType && i == rt.NumOut()-1:
+			errIndex = i
+		default:
+			return cleanupIndex, errIndex, false
+		}
+	}
+	return cleanupIndex, errIndex, true
+}
+
+// hasCleanup 工厂方法是否返回清理方法
+func (bean *Bean) hasCleanup() bool {
+	if bean.factoryMethod == nil {
+		return false
+	}
+	cleanupIndex, _, _ := factoryReturns(reflect.TypeOf(bean.factoryMethod))
+	return cleanupIndex > 0
+}
+
+// instanceType 获取bean实例的类型,结构体类型统一为指针
+func (bean *Bean) instanceType() reflect.Type {
+	if bean.model != nil {
+		return reflect.TypeOf(bean.model)
+	}
+	if bean.factoryMethod != nil {
+		rt := reflect.TypeOf(bean.factoryMethod)
+		if rt.NumOut() == 0 {
+			return nil
+		}
+		if rt.Out(0).Kind() == reflect.Struct {
+			return reflect.PtrTo(rt.Out(0))
+		}
+		return rt.Out(0)
+	}
+	return nil
+}
+
+func (bean *Bean) SetIsSingleton(isSingleton bool) *Bean {
+	bean.isSingleton = isSingleton
+	return bean
+}
+
+// SetLazy 设置懒加载,懒加载的单例bean在容器初始化时不会被创建,第一次被获取或被依赖时才创建
+func (bean *Bean) SetLazy(lazy bool) *Bean {
+	bean.lazy = lazy
+	return bean
+}
+
+func (bean *Bean) IsLazy() bool {
+	return bean.lazy
+}
+
+// AddAlias 添加别名,按名称查找和name标签都可以使用别名,需要在注册到容器之前添加
+func (bean *Bean) AddAlias(names ...string) *Bean {
+	bean.lock.Lock()
+	defer bean.lock.Unlock()
+	for _, name := range names {
+		if name == "" {
+			panic(errors.NameEmptyError)
+		}
+		if name != bean.name && !bean.hasAlias(name) {
+			bean.aliases = append(bean.aliases, name)
+		}
+	}
+	return bean
+}
+
+func (bean *Bean) hasAlias(name string) bool {
+	return containsString(bean.aliases, name)
+}
+
+// SetPrimary 设置为primary bean,同一类型存在多个候选bean时优先注入
+func (bean *Bean) SetPrimary(isPrimary bool) *Bean {
+	bean.isPrimary = isPrimary
+	return bean
+}
+
+// SetQualifier 设置限定符,qualifier标签与bean名称或限定符相同时匹配
+func (bean *Bean) SetQualifier(qualifier string) *Bean {
+	bean.qualifier = qualifier
+	return bean
+}
+
+// SetParamQualifier 设置工厂方法第index个参数的限定符,作用与字段的qualifier标签相同
+func (bean *Bean) SetParamQualifier(index int, qualifier string) *Bean {
+	for _, point := range bean.injectionPoints {
+		if !point.isField && point.index == index {
+			point.qualifier = qualifier
+			return bean
+		}
+	}
+	panic(errors.TypeNotMatchError.Detail(fmt.Sprintf("factory method of bean %s has no injectable param %d", bean.name, index)))
+}
+
+// AddCondition 添加注册条件,容器初始化时任一条件不满足则移除该bean
+func (bean *Bean) AddCondition(conditions ...Condition) *Bean {
+	bean.lock.Lock()
+	defer bean.lock.Unlock()
+	for _, condition := range conditions {
+		if condition != nil {
+			bean.conditions = append(bean.conditions, condition)
+		}
+	}
+	return bean
+}
+
+// SetProfiles 限定bean只在任一profile激活时注册,以!开头的profile表示该profile未激活
+func (bean *Bean) SetProfiles(profiles ...string) *Bean {
+	return bean.AddCondition(OnProfile(profiles...))
+}
+
+func (bean *Bean) AddBeanPreProcessor(processor BeanPreProcessor) *Bean {
+	bean.lock.Lock()
+	defer bean.lock.Unlock()
+	if processor != nil {
+		if bean.beanPreProcessors == nil {
+			bean.beanPreProcessors = []BeanPreProcessor{processor}
+		} else {
+			bean.beanPreProcessors = append(bean.beanPreProcessors, processor)
+		}
+	}
+	return bean
+}
+
+func (bean *Bean) AddBeanPostProcessor(processor BeanPostProcessor) *Bean {
+	bean.lock.Lock()
+	defer bean.lock.Unlock()
+	if processor != nil {
+		if bean.beanPostProcessors == nil {
+			bean.beanPostProcessors = []BeanPostProcessor{processor}
+		} else {
+			bean.beanPostProcessors = append(bean.beanPostProcessors, processor)
+		}
+	}
+	return bean
+}
+
+func (bean *Bean) GetName() string {
+	return bean.name
+}
+
+func (bean *Bean) GetModel() interface{} {
+	return bean.model
+}
+
+func (bean *Bean) GetFactoryMethod() interface{} {
+	return bean.factoryMethod
+}
+
+func (bean *Bean) GetPriority() int {
+	return bean.priority
+}
+
+// GetSource 获取bean的注册位置,file:line,未注册时为空
+func (bean *Bean) GetSource() string {
+	return bean.source
+}
+
+func (bean *Bean) GetAliases() []string {
+	return bean.aliases
+}
+
+func (bean *Bean) IsPrimary() bool {
+	return bean.isPrimary
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"reflect"
+	"strings"
+)
+
+const (
+	componentTag     = "bean"
+	scopeOption      = "scope="
+	lazyOption       = "lazy"
+	singletonScope   = "singleton"
+	prototypeScope   = "prototype"
+	componentOptions = "name,scope=singleton|prototype,lazy"
+)
+
+var componentType = reflect.TypeOf(Component{})
+
+// Component 组件标记,嵌入结构体后可以被go-spring scan扫描并自动注册,
+// 嵌入字段的bean标签设置bean名称、作用域和懒加载,如`bean:"userService,scope=prototype,lazy"`
+type Component struct{}
+
+// ComponentOptions bean标签中的选项
+type ComponentOptions struct {
+	Name      string //bean名称,为空时使用默认名称
+	Prototype bool   //是否为原型bean
+	Lazy      bool   //是否懒加载
+}
+
+// ParseComponentTag 解析bean标签,第一项为bean名称,其余为scope=singleton|prototype和lazy选项
+func ParseComponentTag(tag string) (*ComponentOptions, error) {
+	options := &ComponentOptions{}
+	items := strings.Split(tag, ",")
+	options.Name = strings.TrimSpace(items[0])
+	for _, item := range items[1:] {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == lazyOption:
+			options.Lazy = true
+		case strings.HasPrefix(item, scopeOption):
+			switch scope := strings.TrimPrefix(item, scopeOption); scope {
+			case singletonScope:
+				options.Prototype = false
+			case prototypeScope:
+				options.Prototype = true
+			default:
+				return nil, errors.ComponentTagError.Detail(fmt.Sprintf("unknown scope %s in bean tag %q", scope, tag))
+			}
+		default:
+			return nil, errors.ComponentTagError.Detail(fmt.Sprintf("unknown option %s in bean tag %q, expected %s", item, tag, componentOptions))
+		}
+	}
+	return options, nil
+}
+
+// componentOptionsOf 获取嵌入Component字段的bean标签选项,未嵌入Component时返回nil
+func componentOptionsOf(rt reflect.Type) (*ComponentOptions, error) {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		if f := rt.Field(i); f.Anonymous && f.Type == componentType {
+			return ParseComponentTag(f.Tag.Get(componentTag))
+		}
+	}
+	return nil, nil
+}
+
+// apply 将标签选项应用到bean
+func (options *ComponentOptions) apply(bean *Bean) {
+	if options.Name != "" {
+		bean.name, bean.implicitName = options.Name, false
+	}
+	bean.isSingleton = !options.Prototype
+	bean.lazy = options.Lazy
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Condition bean注册条件,容器初始化时在实例化bean之前判断,不满足条件的bean会从容器中移除,
+// 先按注册顺序判断配置、环境变量、profile等条件,再反复判断OnBean、OnMissingBean直到结果不再变化
+type Condition interface {
+	Matches(c *Container) bool
+}
+
+// ConditionFunc 函数形式的注册条件
+type ConditionFunc func(c *Container) bool
+
+func (f ConditionFunc) Matches(c *Container) bool {
+	return f(c)
+}
+
+// OnProperty 配置项key的值等于value时满足条件
+func OnProperty(key string, value string) Condition {
+	return ConditionFunc(func(c *Container) bool {
+		config := c.GetConfiguration().GetConfig(key)
+		return config != nil && fmt.Sprint(config) == value
+	})
+}
+
+// OnEnv 环境变量name的值等于value时满足条件,value为空时只要求环境变量存在
+func OnEnv(name string, value string) Condition {
+	return ConditionFunc(func(c *Container) bool {
+		env, ok := os.LookupEnv(name)
+		return ok && (value == "" || env == value)
+	})
+}
+
+// beanCondition 依赖其他bean是否注册的条件,在其他条件判断完成之后判断
+type beanCondition struct {
+	target  interface{}
+	missing bool //是否要求bean不存在
+}
+
+func (condition *beanCondition) Matches(c *Container) bool {
+	return c.hasBean(condition.target) != condition.missing
+}
+
+// OnBean 容器中存在target对应的bean时满足条件,target为bean名称、reflect.Type或该类型的值,接口类型使用(*Interface)(nil)
+func OnBean(target interface{}) Condition {
+	return &beanCondition{target: target}
+}
+
+// OnMissingBean 容器中不存在target对应的bean时满足条件,target规则与OnBean相同
+func OnMissingBean(target interface{}) Condition {
+	return &beanCondition{target: target, missing: true}
+}
+
+// OnProfile 任一profile处于激活状态时满足条件,以!开头的profile表示该profile未激活
+func OnProfile(profiles ...string) Condition {
+	return ConditionFunc(func(c *Container) bool {
+		active := map[string]bool{}
+		for _, profile := range c.ActiveProfiles() {
+			active[profile] = true
+		}
+		for _, profile := range profiles {
+			if strings.HasPrefix(profile, "!") && !active[profile[1:]] || active[profile] {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (c *Container) hasBean(target interface{}) bool {
+	if name, ok := target.(string); ok {
+		return c.lookup(name) != nil
+	}
+	rt, ok := target.(reflect.Type)
+	if !ok {
+		rt = reflect.TypeOf(target)
+	}
+	if rt == nil {
+		return false
+	}
+	if rt.Kind() == reflect.Ptr && rt.Elem().Kind() == reflect.Interface {
+		rt = rt.Elem()
+	}
+	return len(c.candidates(rt)) > 0
+}
+
+// evaluateConditions 判断bean的注册条件,移除不满足条件的bean。先按注册顺序判断不依赖其他bean的条件,
+// 再反复判断OnBean和OnMissingBean,每轮优先移除OnBean不满足的bean,因为移除bean只会让OnBean更难满足,
+// 没有这样的bean时才移除OnMissingBean不满足的bean,直到没有bean被移除
+func (c *Container) evaluateConditions() error {
+	beans := make([]*Bean, 0, len(c.beans))
+	for _, name := range c.beanNames() {
+		beans = append(beans, c.beans[name])
+	}
+	sortByRegistration(beans)
+	var remaining []*Bean
+	for _, bean := range beans {
+		matched, _, err := c.matchConditions(bean, false)
+		if err != nil {
+			return err
+		}
+		if matched {
+			remaining = append(remaining, bean)
+		} else {
+			c.logger.Printf("bean:%s skipped, condition not matched", bean.name)
+			c.removeBean(bean)
+		}
+	}
+	for {
+		var missing, present []*Bean
+		for _, bean := range remaining {
+			matched, onMissing, err := c.matchConditions(bean, true)
+			if err != nil {
+				return err
+			}
+			if matched {
+				continue
+			}
+			if onMissing {
+				missing = append(missing, bean)
+			} else {
+				present = append(present, bean)
+			}
+		}
+		removed := present
+		if len(removed) == 0 {
+			removed = missing
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		for _, bean := range removed {
+			c.logger.Printf("bean:%s skipped, condition not matched", bean.name)
+			c.removeBean(bean)
+		}
+		remaining = nil
+		for _, bean := range beans {
+			if c.beans[bean.name] == bean {
+				remaining = append(remaining, bean)
+			}
+		}
+	}
+}
+
+// matchConditions 判断bean的条件,byBean为true时只判断OnBean和OnMissingBean,否则只判断其余条件,
+// 不满足时onMissing表示是否只有OnMissingBean条件不满足
+func (c *Container) matchConditions(bean *Bean, byBean bool) (matched bool, onMissing bool, err error) {
+	matched, onMissing = true, true
+	for _, condition := range bean.conditions {
+		beanCond, isBeanCond := condition.(*beanCondition)
+		if isBeanCond != byBean {
+			continue
+		}
+		var ok bool
+		if err := catch(func() { ok = condition.Matches(c) }); err != nil {
+			return false, false, fmt.Errorf("evaluate condition of bean %s failed: %w", bean.name, err)
+		}
+		if !ok {
+			matched = false
+			if !isBeanCond || !beanCond.missing {
+				return false, false, nil
+			}
+		}
+	}
+	return matched, onMissing, nil
+}
+
+// removeBean 从容器中移除bean及其别名
+func (c *Container) removeBean(bean *Bean) {
+	delete(c.beans, bean.name)
+	c.unindexType(bean)
+	for _, alias := range bean.aliases {
+		if c.aliases[alias] == bean.name {
+			delete(c.aliases, alias)
+		}
+	}
+}
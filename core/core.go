@@ -1,16 +1,23 @@
 package core
 
 import (
+	"context"
+	"fmt"
 	"github.com/kgip/go-spring/configuration"
 	errors "github.com/kgip/go-spring/error"
+	"io"
 	"log"
+	"os"
+	"os/signal"
 	"reflect"
 	"sort"
 	"sync"
+	"syscall"
+	"time"
 )
 
-var (
-	once = &sync.Once{}
+const (
+	defaultDestroyTimeout = 30 * time.Second
 )
 
 func GetPriority(o interface{}) int {
@@ -23,12 +30,34 @@ func GetPriority(o interface{}) int {
 // Container ioc容器
 type Container struct {
 	beans                    map[string]*Bean
+	aliases                  map[string]string        //别名到bean名称的映射
+	types                    map[reflect.Type]*Bean   //类型索引,类型到以其默认名称注册的bean的映射
+	ambiguous                map[string][]string      //有歧义的短名称到包路径限定名称的映射
+	registered               int                      //已注册的bean数量,用于记录注册顺序
+	graph                    map[string][]*dependency //bean名称到其依赖的映射,注册bean时更新,初始化时重新构建
 	configuration            configuration.Provider
 	globalBeanPreProcessors  []BeanPreProcessor
 	globalBeanPostProcessors []BeanPostProcessor
 	containerPreProcessors   []ContainerPreProcessor
 	containerPostProcessors  []ContainerPostProcessor
-	isInited                 bool //是否已经初始化
+	isInited                 bool                                    //是否已经初始化
+	initErr                  error                                   //初始化错误
+	once                     *sync.Once                              //保证每个容器只初始化一次
+	isShutdown               bool                                    //是否已经关闭
+	singletons               []*Bean                                 //已创建的单例bean,按创建完成的顺序排列
+	creation                 *reentrantMutex                         //bean创建锁,运行时通过延迟注入并发获取bean时保证同一时间只有一个goroutine创建bean
+	creating                 map[*Bean]bool                          //持有创建锁的goroutine正在创建的bean,用于检测循环依赖
+	singletonFactories       map[string]func() interface{}           //正在创建的单例bean的提前引用工厂
+	earlySingletons          map[string]interface{}                  //已被提前引用的正在创建的单例bean
+	proxyInstances           map[string]map[reflect.Type]interface{} //结构体单例bean在注入点按接口创建的代理
+	strictCycles             bool                                    //严格模式下拒绝所有循环依赖
+	strictInjection          bool                                    //严格注入模式下依赖不存在时报错,不再注入默认值
+	overridePolicy           OverridePolicy                          //同名bean的覆盖策略
+	overrides                []BeanOverride                          //同名bean覆盖的记录
+	listeners                []*listener                             //已注册的事件监听器
+	events                   sync.WaitGroup                          //正在处理事件的异步监听器
+	eventsClosed             bool                                    //容器关闭后不再发布事件
+	destroyTimeout           time.Duration                           //单个bean的销毁超时时间
 	logger                   *log.Logger
 	lock                     *sync.Mutex
 	rv                       *reflect.Value
@@ -36,84 +65,403 @@ type Container struct {
 
 func NewContainer(configurationProvider configuration.Provider, logger *log.Logger) *Container {
 	c := &Container{
-		beans:         map[string]*Bean{},
-		lock:          &sync.Mutex{},
-		configuration: configurationProvider,
-		logger:        logger}
+		beans:              map[string]*Bean{},
+		aliases:            map[string]string{},
+		types:              map[reflect.Type]*Bean{},
+		ambiguous:          map[string][]string{},
+		creation:           &reentrantMutex{},
+		creating:           map[*Bean]bool{},
+		graph:              map[string][]*dependency{},
+		singletonFactories: map[string]func() interface{}{},
+		earlySingletons:    map[string]interface{}{},
+		proxyInstances:     map[string]map[reflect.Type]interface{}{},
+		lock:               &sync.Mutex{},
+		once:               &sync.Once{},
+		configuration:      configurationProvider,
+		destroyTimeout:     defaultDestroyTimeout,
+		logger:             logger}
 	rv := reflect.ValueOf(c)
 	c.rv = &rv
 	return c
 }
 
-// Init 容器初始化方法
+// Init 容器初始化方法,初始化失败时panic
 func (c *Container) Init() {
-	once.Do(func() {
-		c.isInited = true
-		c.logger.Println("Ioc container start init....")
-		if c.containerPreProcessors != nil {
-			sort.Slice(c.containerPreProcessors, func(i, j int) bool {
-				return GetPriority(c.containerPreProcessors[i]) > GetPriority(c.containerPreProcessors[j])
-			})
-			for _, processor := range c.containerPreProcessors {
-				processor.PreProcess(c)
+	if err := c.InitE(); err != nil {
+		panic(err)
+	}
+}
+
+// InitE 容器初始化方法,返回所有创建失败的bean及其创建路径组成的聚合错误,
+// 初始化失败时关闭容器,停止并销毁已经创建的bean
+func (c *Container) InitE() error {
+	c.once.Do(func() {
+		if c.initErr = c.init(); c.initErr != nil {
+			if err := c.Shutdown(context.Background()); err != nil {
+				c.logger.Println(err)
 			}
 		}
-		//加载配置
-		c.logger.Printf("Start loading the configuration")
-		c.configuration.Load()
-		c.logger.Println("Load configuration complete")
-		//实例化单例bean
-		for name := range c.beans {
-			c.GetBeanInstanceByName(name)
-		}
-		c.logger.Println("Ioc container instance beans complete")
-		c.isInited = true
-		if c.containerPostProcessors != nil {
-			sort.Slice(c.containerPostProcessors, func(i, j int) bool {
-				return GetPriority(c.containerPostProcessors[i]) > GetPriority(c.containerPostProcessors)
-			})
-			for _, processor := range c.containerPostProcessors {
-				processor.PostProcess(c)
+	})
+	return c.initErr
+}
+
+func (c *Container) init() error {
+	c.isInited = true
+	c.logger.Println("Ioc container start init....")
+	if c.containerPreProcessors != nil {
+		sort.Slice(c.containerPreProcessors, func(i, j int) bool {
+			return GetPriority(c.containerPreProcessors[i]) > GetPriority(c.containerPreProcessors[j])
+		})
+		for _, processor := range c.containerPreProcessors {
+			if err := catch(func() { processor.PreProcess(c) }); err != nil {
+				return errors.ProcessorError.Detail(err.Error())
 			}
 		}
-		c.logger.Println("Ioc container init complete")
-	})
+	}
+	//加载配置
+	c.logger.Printf("Start loading the configuration")
+	if err := catch(c.configuration.Load); err != nil {
+		return errors.ConfigLoadError.Detail(err.Error())
+	}
+	c.logger.Println("Load configuration complete")
+	if notifier, ok := c.configuration.(configuration.ChangeNotifier); ok {
+		notifier.OnChange(func() {
+			if err := c.Publish(ConfigChangedEvent{Container: c}); err != nil {
+				c.logger.Println(err)
+			}
+		})
+	}
+	//判断bean的注册条件
+	if err := c.evaluateConditions(); err != nil {
+		return err
+	}
+	//构建依赖图并按拓扑顺序实例化bean,作为依赖已经创建失败的bean不再重复创建
+	if err := c.buildGraph(); err != nil {
+		return err
+	}
+	sorted, err := c.sortBeans()
+	if err != nil {
+		return err
+	}
+	var errs errors.MultiError
+	failed := map[string]bool{}
+	//先创建监听器bean,使其可以收到其他bean的创建事件
+	for _, bean := range listenersFirst(sorted) {
+		if failed[bean.name] || bean.lazy {
+			continue
+		}
+		if _, err := c.getBean(bean); err != nil {
+			if creationErr, ok := err.(*errors.BeanCreationError); ok {
+				for _, beanName := range creationErr.Path {
+					failed[beanName] = true
+				}
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	c.logger.Println("Ioc container instance beans complete")
+	if c.containerPostProcessors != nil {
+		sort.Slice(c.containerPostProcessors, func(i, j int) bool {
+			return GetPriority(c.containerPostProcessors[i]) > GetPriority(c.containerPostProcessors[j])
+		})
+		for _, processor := range c.containerPostProcessors {
+			if err := catch(func() { processor.PostProcess(c) }); err != nil {
+				return errors.ProcessorError.Detail(err.Error())
+			}
+		}
+	}
+	//所有bean装配完成后按阶段启动Lifecycle bean
+	if err := c.startLifecycles(context.Background()); err != nil {
+		return err
+	}
+	//所有bean已经启动,监听器处理启动事件失败只记录日志
+	if err := c.Publish(ContainerStartedEvent{Container: c}); err != nil {
+		c.logger.Println(err)
+	}
+	c.logger.Println("Ioc container init complete")
+	return nil
 }
 
-// GetBeanInstanceByName 获取bean
-func (c *Container) GetBeanInstanceByName(name string) interface{} {
+// catch 执行f并将其中的panic转换为error
+func catch(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Recover(r)
+		}
+	}()
+	f()
+	return nil
+}
+
+// Shutdown 关闭容器,按阶段的逆序停止正在运行的Lifecycle bean,再按创建顺序的逆序销毁已创建的单例bean,单个bean销毁失败不影响其余bean,
+// 最后关闭实现了io.Closer的配置提供者
+func (c *Container) Shutdown(ctx context.Context) error {
+	c.lock.Lock()
+	if c.isShutdown {
+		c.lock.Unlock()
+		return nil
+	}
+	c.isShutdown = true
+	singletons := c.singletons
+	c.lock.Unlock()
+	c.logger.Println("Ioc container start shutdown....")
+	var errs errors.MultiError
+	if err := c.Publish(ContainerClosedEvent{Container: c}); err != nil {
+		c.logger.Println(err)
+		errs = append(errs, err)
+	}
+	if err := c.waitEvents(ctx); err != nil {
+		c.logger.Println(err)
+		errs = append(errs, err)
+	}
+	//按启动顺序的逆序停止Lifecycle bean,再销毁其依赖
+	errs = append(errs, c.stopLifecycles(ctx, c.lifecycles(singletons))...)
+	for i := len(singletons) - 1; i >= 0; i-- {
+		if err := c.destroyBean(ctx, singletons[i]); err != nil {
+			c.logger.Println(err)
+			errs = append(errs, err)
+		}
+	}
+	//停止监听配置文件等配置提供者持有的资源
+	if closer, ok := c.configuration.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			c.logger.Println(err)
+			errs = append(errs, err)
+		}
+	}
+	c.logger.Println("Ioc container shutdown complete")
+	return errs.ErrorOrNil()
+}
+
+// destroyBean 销毁bean,依次调用Destroyer或io.Closer以及工厂方法返回的清理方法,超过destroyTimeout或ctx结束时返回超时错误
+func (c *Container) destroyBean(ctx context.Context, bean *Bean) error {
+	var destroy func(ctx context.Context) error
+	switch instance := bean.instance.(type) {
+	case Destroyer:
+		destroy = instance.Destroy
+	case io.Closer:
+		destroy = func(context.Context) error {
+			return instance.Close()
+		}
+	}
+	if bean.cleanup != nil {
+		closeInstance := destroy
+		destroy = func(ctx context.Context) error {
+			defer bean.cleanup()
+			if closeInstance != nil {
+				return closeInstance(ctx)
+			}
+			return nil
+		}
+	}
+	if destroy == nil {
+		return nil
+	}
+	c.logger.Printf("start destroying bean:%s", bean.name)
+	ctx, cancel := context.WithTimeout(ctx, c.destroyTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%v", r)
+			}
+		}()
+		done <- destroy(ctx)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return errors.DestroyBeanError.Detail(fmt.Sprintf("bean %s: %v", bean.name, err))
+		}
+	case <-ctx.Done():
+		return errors.DestroyTimeoutError.Detail(fmt.Sprintf("bean %s: %v", bean.name, ctx.Err()))
+	}
+	c.logger.Printf("destroy bean:%s complete", bean.name)
+	return nil
+}
+
+// EnableShutdownHook 监听系统信号(默认SIGINT、SIGTERM),收到信号后关闭容器,
+// 返回的channel在关闭完成后接收Shutdown的结果并被关闭,是否退出进程由调用方决定
+func (c *Container) EnableShutdownHook(signals ...os.Signal) <-chan error {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		defer close(done)
+		sig := <-ch
+		signal.Stop(ch)
+		c.logger.Printf("received signal %s, shutdown ioc container", sig)
+		done <- c.Shutdown(context.Background())
+	}()
+	return done
+}
+
+// SetDestroyTimeout 设置单个bean的销毁超时时间
+func (c *Container) SetDestroyTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		c.destroyTimeout = timeout
+	}
+}
+
+// lookup 按名称或别名查找bean
+func (c *Container) lookup(name string) *Bean {
 	if bean := c.beans[name]; bean != nil {
-		if bean.isCreating && bean.factoryMethod != nil {
-			panic(errors.CircularReferenceError)
+		return bean
+	}
+	if alias, ok := c.aliases[name]; ok {
+		return c.beans[alias]
+	}
+	return nil
+}
+
+// GetBeanInstanceByName 获取bean,name可以是bean名称或别名,bean不存在时返回nil,创建失败时panic
+func (c *Container) GetBeanInstanceByName(name string) interface{} {
+	if c.lookup(name) == nil {
+		return nil
+	}
+	instance, err := c.GetBeanInstanceByNameE(name)
+	if err != nil {
+		panic(err)
+	}
+	return instance
+}
+
+// GetBeanInstanceByNameE 获取bean,bean不存在时返回UnknownBeanNameError
+func (c *Container) GetBeanInstanceByNameE(name string) (interface{}, error) {
+	bean := c.lookup(name)
+	if bean == nil {
+		if err := c.ambiguousNameError(name); err != nil {
+			return nil, err
 		}
-		if bean.isSingleton && bean.instance != nil {
-			return bean.instance
+		return nil, errors.UnknownBeanNameError.Detail(fmt.Sprintf("unknown bean name: %s", name))
+	}
+	return c.getBean(bean)
+}
+
+// getBean 获取bean实例,需要创建时持有创建锁,同一goroutine中递归获取依赖的bean可以重入
+func (c *Container) getBean(bean *Bean) (interface{}, error) {
+	c.creation.Lock()
+	defer c.creation.Unlock()
+	if bean.isSingleton && bean.instance != nil {
+		return bean.instance, nil
+	}
+	if c.creating[bean] {
+		if early := c.getEarlyReference(bean); early != nil {
+			return early, nil
 		}
-		return c.instanceBean(bean)
+		return nil, errors.CircularReferenceError.Detail(fmt.Sprintf("bean %s is being created", bean.name))
+	}
+	return c.instanceBean(bean)
+}
+
+// getEarlyReference 获取正在创建的单例bean的提前引用,第一次获取时通过singletonFactories创建并移入earlySingletons,严格模式下不提供提前引用
+func (c *Container) getEarlyReference(bean *Bean) interface{} {
+	if c.strictCycles || !bean.isSingleton {
+		return nil
+	}
+	if early, ok := c.earlySingletons[bean.name]; ok {
+		return early
+	}
+	if factory := c.singletonFactories[bean.name]; factory != nil {
+		early := factory()
+		c.earlySingletons[bean.name] = early
+		delete(c.singletonFactories, bean.name)
+		return early
 	}
 	return nil
 }
 
-// GetBeanInstanceByStruct 通过结构体获取实例化的bean
+// isEarlyReferenced bean是否已经被提前引用
+func (c *Container) isEarlyReferenced(bean *Bean) bool {
+	_, ok := c.earlySingletons[bean.name]
+	return ok
+}
+
+// earlyReference 创建提前引用,依次调用EarlyReferencePostProcessor替换原始实例
+func (c *Container) earlyReference(bean *Bean, instance interface{}) interface{} {
+	for _, processor := range bean.beanPostProcessors {
+		if early, ok := processor.(EarlyReferencePostProcessor); ok {
+			var err error
+			if instance, err = early.EarlyReference(c, bean, instance); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return instance
+}
+
+// sameInstance 判断两个bean实例是否为同一个对象,不可比较的类型比较其指针
+func sameInstance(a, b interface{}) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return false
+	}
+	if ta == nil || ta.Comparable() {
+		return a == b
+	}
+	switch ta.Kind() {
+	case reflect.Func, reflect.Map, reflect.Slice:
+		return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+	}
+	return false
+}
+
+// SetStrictInjection 设置严格注入模式,严格模式下bean类型的依赖不存在时报错,autowired:"optional"的字段除外
+func (c *Container) SetStrictInjection(strict bool) {
+	c.checkInited()
+	c.strictInjection = strict
+}
+
+// SetStrictCycles 设置严格模式,严格模式下拒绝所有循环依赖,否则允许单例bean之间通过字段注入形成循环依赖
+func (c *Container) SetStrictCycles(strict bool) {
+	c.checkInited()
+	c.strictCycles = strict
+}
+
+// GetBeanInstanceByStruct 通过结构体获取实例化的bean,规则与字段注入相同,先按完整类型查找默认名称的bean,
+// 再查找显式命名的候选bean,存在多个候选bean时选择primary bean,bean不存在时返回nil
 func (c *Container) GetBeanInstanceByStruct(value interface{}) (interface{}, error) {
-	//获取bean名称
 	if beanNameProvider, ok := value.(BeanNameProvider); ok {
-		return c.GetBeanInstanceByName(beanNameProvider.GetBeanName()), nil
+		name := beanNameProvider.GetBeanName()
+		if c.lookup(name) == nil {
+			return nil, nil
+		}
+		return c.GetBeanInstanceByNameE(name)
 	}
 	rt := reflect.TypeOf(value)
-	if rt.Kind() == reflect.Ptr {
-		rt = rt.Elem()
-	}
-	if rt.Kind() != reflect.Struct {
+	if rt == nil || rt.Kind() == reflect.Ptr && rt.Elem().Kind() != reflect.Struct || rt.Kind() != reflect.Ptr && rt.Kind() != reflect.Struct {
 		return nil, errors.TypeNotMatchError
 	}
-	return c.GetBeanInstanceByName(rt.Name()), nil
+	bean, err := c.resolveBean(&injectionPoint{rt: rt})
+	if err != nil || bean == nil {
+		return nil, err
+	}
+	return c.getBean(bean)
 }
 
 func (c *Container) GetConfiguration() configuration.Provider {
 	return c.configuration
 }
 
+// ActiveProfiles 获取激活的profile,配置提供者未实现configuration.ProfileProvider时返回nil
+func (c *Container) ActiveProfiles() []string {
+	if provider, ok := c.configuration.(configuration.ProfileProvider); ok {
+		return provider.ActiveProfiles()
+	}
+	return nil
+}
+
+func (c *Container) GetLogger() *log.Logger {
+	return c.logger
+}
+
 func (c *Container) SetConfiguration(provider configuration.Provider) {
 	c.configuration = provider
 }
@@ -124,13 +472,54 @@ func (c *Container) checkInited() {
 	}
 }
 
-// AddBean 添加bean
+// checkAliases 检查bean名称和别名是否与其他bean的名称或别名冲突
+func (c *Container) checkAliases(bean *Bean) error {
+	if target, ok := c.aliases[bean.name]; ok && target != bean.name {
+		return errors.AliasCollisionError.Detail(fmt.Sprintf("bean name %s is already an alias of bean %s", bean.name, target))
+	}
+	for _, alias := range bean.aliases {
+		if existing := c.beans[alias]; existing != nil && alias != bean.name {
+			return errors.AliasCollisionError.Detail(fmt.Sprintf("alias %s of bean %s collides with bean name %s", alias, bean.name, alias))
+		}
+		if target, ok := c.aliases[alias]; ok && target != bean.name {
+			return errors.AliasCollisionError.Detail(fmt.Sprintf("alias %s of bean %s is already an alias of bean %s", alias, bean.name, target))
+		}
+	}
+	return nil
+}
+
+// AddBean 添加bean,按覆盖策略禁止覆盖或别名冲突时panic
 func (c *Container) AddBean(bean *Bean) bool {
+	added, err := c.AddBeanE(bean)
+	if err != nil {
+		panic(err)
+	}
+	return added
+}
+
+// AddBeanE 添加bean,同名bean按覆盖策略处理,返回bean是否被添加
+func (c *Container) AddBeanE(bean *Bean) (bool, error) {
 	c.checkInited()
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	if c.beans[bean.name] != nil && GetPriority(c.beans[bean.name]) > GetPriority(bean) {
-		return false
+	if !bean.isSingleton && bean.hasCleanup() {
+		//原型bean的实例不由容器管理,容器关闭时无法调用每个实例的清理方法
+		return false, errors.BeanIllegalError.Detail(fmt.Sprintf("prototype bean %s can't use a factory method returning a cleanup func", bean.name))
+	}
+	bean.source = registrationSite()
+	rebuild := c.qualifyName(bean)
+	if err := c.checkAliases(bean); err != nil {
+		return false, err
+	}
+	if existing := c.beans[bean.name]; existing != nil {
+		if replace, err := c.override(existing, bean); err != nil || !replace {
+			return false, err
+		}
+		c.unindexType(existing)
+		rebuild = true
+	}
+	for _, alias := range bean.aliases {
+		c.aliases[alias] = bean.name
 	}
 	if c.globalBeanPreProcessors != nil {
 		if bean.beanPreProcessors == nil {
@@ -152,8 +541,12 @@ func (c *Container) AddBean(bean *Bean) bool {
 			})
 		}
 	}
+	c.registered++
+	bean.order = c.registered
 	c.beans[bean.name] = bean
-	return true
+	c.indexType(bean)
+	c.updateGraph(bean, rebuild)
+	return true, nil
 }
 
 func (c *Container) AddBeanPreProcessor(processor BeanPreProcessor) {
@@ -208,10 +601,23 @@ func (c *Container) AddContainerPostProcessor(processor ContainerPostProcessor)
 	}
 }
 
-// instanceBean 实例化bean
-func (c *Container) instanceBean(bean *Bean) interface{} {
+// instanceBean 实例化bean,处理器和初始化方法中的panic会被转换为BeanCreationError
+func (c *Container) instanceBean(bean *Bean) (instance interface{}, err error) {
 	c.logger.Printf("start creating bean:%s", bean.name)
-	bean.isCreating = true
+	c.creating[bean] = true
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Recover(r)
+		}
+		delete(c.creating, bean)
+		delete(c.singletonFactories, bean.name)
+		delete(c.earlySingletons, bean.name)
+		if err != nil {
+			bean.instance = nil
+			instance = nil
+			err = errors.WrapCreationError(bean.name, err)
+		}
+	}()
 	//调用前置处理器
 	if bean.beanPreProcessors != nil {
 		for _, processor := range bean.beanPreProcessors {
@@ -224,225 +630,243 @@ func (c *Container) instanceBean(bean *Bean) interface{} {
 		method := reflect.ValueOf(bean.factoryMethod)
 		//实例化方法参数
 		args := make([]reflect.Value, method.Type().NumIn())
-		for i := 0; i < method.Type().NumIn(); i++ {
-			in := method.Type().In(i)
+		for i := range args {
 			//如果接收容器指针作为参数，则将参数设置为容器指针
-			if in.Kind() == reflect.Ptr && c.rv.Type().AssignableTo(in) {
+			if isContainerType(method.Type().In(i)) {
 				args[i] = *c.rv
-			} else {
-				args[i] = reflect.ValueOf(c.GetInstance(in))
 			}
 		}
-		//调用工厂方法
-		values := method.Call(args)
+		for _, point := range bean.injectionPoints {
+			if point.isField {
+				continue
+			}
+			if args[point.index], err = c.resolveValue(point); err != nil {
+				return nil, err
+			}
+		}
+		//调用工厂方法,可变参数以切片形式传入
+		var values []reflect.Value
+		if method.Type().IsVariadic() {
+			values = method.CallSlice(args)
+		} else {
+			values = method.Call(args)
+		}
+		cleanupIndex, errIndex, _ := factoryReturns(method.Type())
+		var cleanup func()
+		if cleanupIndex > 0 && !values[cleanupIndex].IsNil() {
+			cleanup = values[cleanupIndex].Interface().(func())
+		}
+		//工厂方法失败时由容器调用已返回的清理方法,释放创建了一半的资源
+		if errIndex > 0 && !values[errIndex].IsNil() {
+			if cleanup != nil {
+				cleanup()
+			}
+			return nil, values[errIndex].Interface().(error)
+		}
 		instanceRv := values[0]
+		if !instanceRv.IsValid() || (instanceRv.Kind() == reflect.Ptr || instanceRv.Kind() == reflect.Interface) && instanceRv.IsNil() {
+			if cleanup != nil {
+				cleanup()
+			}
+			return nil, errors.NilError.Detail(fmt.Sprintf("factory method of bean %s returned nil", bean.name))
+		}
+		if instanceRv.Kind() == reflect.Interface {
+			instanceRv = instanceRv.Elem()
+		}
+		if cleanup != nil {
+			bean.cleanup = cleanup
+		}
 		//保存指针值
-		bean.instance = instanceRv.Interface()
 		if instanceRv.Kind() == reflect.Struct {
-			bean.instance = &bean.instance
+			ptr := reflect.New(instanceRv.Type())
+			ptr.Elem().Set(instanceRv)
+			instanceRv = ptr
 		}
+		instance = instanceRv.Interface()
 	} else {
 		rt := reflect.TypeOf(bean.model).Elem()
-		bean.instance = reflect.New(rt).Interface()
+		instance = reflect.New(rt).Interface()
+	}
+	//单例bean在赋值前提前暴露,用于解决字段注入的循环依赖
+	raw := instance
+	if bean.isSingleton {
+		c.singletonFactories[bean.name] = func() interface{} {
+			return c.earlyReference(bean, raw)
+		}
 	}
 	//调用初始化方法
-	if initializer, ok := bean.instance.(Initializer); ok {
+	if initializer, ok := instance.(Initializer); ok {
 		initializer.Init(c)
 	}
 
-	//调用后置处理器
+	//调用后置处理器,已经被提前引用的bean由EarlyReferencePostProcessor在提前引用时替换
 	if bean.beanPostProcessors != nil {
 		for _, processor := range bean.beanPostProcessors {
-			processor.PostProcess(c, bean.instance)
+			processor.PostProcess(c, instance)
+			replacer, ok := processor.(BeanReplacePostProcessor)
+			if _, early := processor.(EarlyReferencePostProcessor); !ok || early && c.isEarlyReferenced(bean) {
+				continue
+			}
+			if instance, err = replacer.Replace(c, bean, instance); err != nil {
+				return nil, err
+			}
+		}
+	}
+	//被提前引用的bean以提前引用作为最终实例,提前引用之后又被替换时注入到其他bean中的实例与最终实例不一致
+	if early, ok := c.earlySingletons[bean.name]; ok {
+		if !sameInstance(instance, raw) {
+			return nil, errors.EarlyReferenceError.Detail(fmt.Sprintf("bean %s was replaced by a post processor after being injected into other beans through a circular reference", bean.name))
 		}
+		instance = early
 	}
+	bean.instance = instance
 	c.logger.Printf("create bean:%s complete", bean.name)
-	bean.isCreating = false
-	return bean.instance
-}
-
-func (c *Container) GetInstance(rt reflect.Type) interface{} {
-	if rt.Kind() == reflect.Ptr {
-		instance := c.GetInstance(rt.Elem())
-		if instance == nil {
-			return nil
+	if bean.isSingleton {
+		c.lock.Lock()
+		c.singletons = append(c.singletons, bean)
+		c.lock.Unlock()
+		//监听器处理bean创建事件失败不影响bean的创建
+		c.registerListener(bean, instance)
+		if err := c.Publish(BeanCreatedEvent{Name: bean.name, Instance: instance}); err != nil {
+			c.logger.Println(err)
 		}
-		return &instance
-	} else {
-		return c.instanceByType(rt)
-	}
-}
-
-func (c *Container) instanceByType(rt reflect.Type) interface{} {
-	switch rt.Kind() {
-	case reflect.Struct:
-		if instance, err := c.GetBeanInstanceByStruct(reflect.New(rt).Interface()); err != nil {
-			panic(err)
-		} else if instance != nil {
-			return reflect.ValueOf(instance).Elem().Interface()
-		} else { //容器中不存在则创建一个默认对象
-			return reflect.New(rt).Elem().Interface()
-		}
-	case reflect.String:
-		return ""
-	case reflect.Int:
-	case reflect.Int8:
-	case reflect.Int16:
-	case reflect.Int32:
-	case reflect.Int64:
-	case reflect.Uint:
-	case reflect.Uint8:
-	case reflect.Uint16:
-	case reflect.Uint32:
-	case reflect.Uint64:
-		return 0
-	case reflect.Float64:
-	case reflect.Float32:
-		return 0.0
-	case reflect.Bool:
-		return false
-	case reflect.Array:
-		return reflect.New(rt).Elem().Interface()
 	}
-	return nil
+	return instance, nil
 }
 
-// Bean 表示一个对象
-type Bean struct {
-	name               string
-	isCreating         bool //是否正在被创建
-	priority           int
-	model              interface{} //原始对象,struct指针
-	instance           interface{} //创建完成后并赋值后的实例指针
-	factoryMethod      interface{} //实例化工厂方法
-	isSingleton        bool        //是否单例
-	beanPreProcessors  []BeanPreProcessor
-	beanPostProcessors []BeanPostProcessor
-	lock               *sync.Mutex
-}
-
-func NewBean(model interface{}) *Bean {
-	bean := &Bean{lock: &sync.Mutex{}, isSingleton: true}
-	bean.SetModel(model)
-	if provider, ok := bean.model.(BeanNameProvider); ok {
-		bean.SetName(provider.GetBeanName())
-	}
-	if bean.name == "" {
-		bean.SetName(reflect.TypeOf(bean.model).Elem().Name())
+// GetInstance 获取rt类型的实例,失败时panic
+func (c *Container) GetInstance(rt reflect.Type) interface{} {
+	value, err := c.GetInstanceE(rt)
+	if err != nil {
+		panic(err)
 	}
-	return bean
+	return value.Interface()
 }
 
-func NewFactoryBean(factoryMethod interface{}) *Bean {
-	bean := &Bean{lock: &sync.Mutex{}, isSingleton: true}
-	bean.SetFactoryMethod(factoryMethod)
-	if provider, ok := bean.factoryMethod.(BeanNameProvider); ok {
-		bean.SetName(provider.GetBeanName())
+// GetInstanceE 获取rt类型的实例,接收容器指针时返回容器本身,容器中不存在对应bean时返回零值
+func (c *Container) GetInstanceE(rt reflect.Type) (reflect.Value, error) {
+	if isContainerType(rt) {
+		return *c.rv, nil
 	}
-	if bean.name == "" {
-		rt := reflect.TypeOf(bean.factoryMethod).In(0)
-		if rt.Kind() == reflect.Ptr {
-			bean.SetName(rt.Elem().Name())
-		} else {
-			bean.SetName(rt.Name())
+	if rt.Kind() == reflect.Ptr && rt.Elem().Kind() == reflect.Struct {
+		instance, err := c.GetBeanInstanceByStruct(reflect.New(rt.Elem()).Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if value, ok := convertInstance(instance, rt); ok {
+			return value, nil
 		}
+		//容器中不存在则创建一个默认对象
+		return reflect.New(rt.Elem()), nil
 	}
-	return bean
+	return c.instanceByType(rt)
 }
 
-func (bean *Bean) SetName(name string) *Bean {
-	if name != "" {
-		bean.name = name
-	} else {
-		panic(errors.NameEmptyError)
+func (c *Container) instanceByType(rt reflect.Type) (reflect.Value, error) {
+	if rt.Kind() == reflect.Struct {
+		instance, err := c.GetBeanInstanceByStruct(reflect.New(rt).Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if value, ok := convertInstance(instance, rt); ok {
+			return value, nil
+		}
 	}
-	return bean
-}
-
-func (bean *Bean) SetPriority(priority int) *Bean {
-	bean.priority = priority
-	return bean
+	//容器中不存在则返回零值
+	return reflect.New(rt).Elem(), nil
 }
 
-func (bean *Bean) SetModel(model interface{}) *Bean {
-	if model == nil {
-		panic(errors.NilError)
+// resolveValue 获取注入点的值,依赖的bean不存在时按类型获取默认值,延迟注入点在调用时才获取bean
+func (c *Container) resolveValue(point *injectionPoint) (reflect.Value, error) {
+	if target := lazyTarget(point.rt); target != nil {
+		return c.lazyValue(point, target), nil
 	}
-	rt := reflect.TypeOf(model)
-	if rt.Kind() == reflect.Ptr && rt.Elem().Kind() != reflect.Struct || rt.Kind() != reflect.Struct {
-		panic(errors.TypeNotMatchError)
+	if point.beanName == "" {
+		if elem := collectionElem(point.rt); elem != nil {
+			return c.resolveCollection(point, elem)
+		}
 	}
-	if rt.Kind() == reflect.Struct {
-		bean.model = &model
-	} else {
-		bean.model = model
+	bean, err := c.resolveBean(point)
+	if err != nil {
+		return reflect.Value{}, err
 	}
-	return bean
-}
-
-func (bean *Bean) SetFactoryMethod(method interface{}) *Bean {
-	if method == nil {
-		panic(errors.NilError)
+	if bean == nil {
+		if point.optional {
+			return reflect.Zero(point.rt), nil
+		}
+		if c.unsatisfied(point) {
+			return reflect.Value{}, errors.NoSuchBeanError.Detail(fmt.Sprintf("unsatisfied dependency %s of type %s", point, point.rt))
+		}
+		return c.GetInstanceE(point.rt)
 	}
-	rt := reflect.TypeOf(method)
-	if rt.Kind() != reflect.Func {
-		panic(errors.TypeNotMatchError)
+	instance, err := c.getBean(bean)
+	if err != nil {
+		return reflect.Value{}, err
 	}
-	if rt.NumIn() != 1 {
-		panic(errors.FactoryMethodReturnsError)
-	} else {
-		returnRt := rt.In(0)
-		if returnRt.Kind() == reflect.Ptr && returnRt.Elem().Kind() != reflect.Struct || returnRt.Kind() != reflect.Struct {
-			panic(errors.TypeNotMatchError)
-		}
+	if instance, err = c.proxyFor(bean, instance, point.rt); err != nil {
+		return reflect.Value{}, err
 	}
-	bean.factoryMethod = method
-	return bean
-}
-
-func (bean *Bean) SetIsSingleton(isSingleton bool) *Bean {
-	bean.isSingleton = isSingleton
-	return bean
+	if value, ok := convertInstance(instance, point.rt); ok {
+		return value, nil
+	}
+	return reflect.Value{}, errors.TypeNotMatchError.Detail(fmt.Sprintf("bean %s can't be assigned to %s of type %s", bean.name, point, point.rt))
 }
 
-func (bean *Bean) AddBeanPreProcessor(processor BeanPreProcessor) *Bean {
-	bean.lock.Lock()
-	defer bean.lock.Unlock()
-	if processor != nil {
-		if bean.beanPreProcessors == nil {
-			bean.beanPreProcessors = []BeanPreProcessor{processor}
-		} else {
-			bean.beanPreProcessors = append(bean.beanPreProcessors, processor)
+// resolveCollection 获取集合注入点的值,切片按优先级从高到低排序,map以bean名称为key
+func (c *Container) resolveCollection(point *injectionPoint, elem reflect.Type) (reflect.Value, error) {
+	beans, err := c.resolveBeans(point)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	values := make([]reflect.Value, len(beans))
+	priorities := make(map[*Bean]int, len(beans))
+	for i, bean := range beans {
+		instance, err := c.getBean(bean)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		proxy, err := c.proxyFor(bean, instance, elem)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		values[i], _ = convertInstance(proxy, elem)
+		//bean未设置优先级时使用实例的PriorityProvider
+		if priorities[bean] = bean.priority; bean.priority == 0 {
+			priorities[bean] = GetPriority(instance)
 		}
 	}
-	return bean
-}
-
-func (bean *Bean) AddBeanPostProcessor(processor BeanPostProcessor) *Bean {
-	bean.lock.Lock()
-	defer bean.lock.Unlock()
-	if processor != nil {
-		if bean.beanPostProcessors == nil {
-			bean.beanPostProcessors = []BeanPostProcessor{processor}
-		} else {
-			bean.beanPostProcessors = append(bean.beanPostProcessors, processor)
+	if point.rt.Kind() == reflect.Map {
+		collection := reflect.MakeMapWithSize(point.rt, len(beans))
+		for i, bean := range beans {
+			collection.SetMapIndex(reflect.ValueOf(bean.name).Convert(point.rt.Key()), values[i])
 		}
+		return collection, nil
 	}
-	return bean
-}
-
-func (bean *Bean) GetName() string {
-	return bean.name
-}
-
-func (bean *Bean) GetModel() interface{} {
-	return bean.model
-}
-
-func (bean *Bean) GetFactoryMethod() interface{} {
-	return bean.factoryMethod
+	indexes := make([]int, len(beans))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return priorities[beans[indexes[i]]] > priorities[beans[indexes[j]]]
+	})
+	collection := reflect.MakeSlice(point.rt, 0, len(beans))
+	for _, i := range indexes {
+		collection = reflect.Append(collection, values[i])
+	}
+	return collection, nil
 }
 
-func (bean *Bean) GetPriority() int {
-	return bean.priority
+// convertInstance 将bean实例转换为rt类型,rt为结构体时取实例指针指向的值
+func convertInstance(instance interface{}, rt reflect.Type) (reflect.Value, bool) {
+	if instance == nil {
+		return reflect.Value{}, false
+	}
+	value := reflect.ValueOf(instance)
+	if value.Type().AssignableTo(rt) {
+		return value, true
+	}
+	if value.Kind() == reflect.Ptr && value.Elem().Type().AssignableTo(rt) {
+		return value.Elem(), true
+	}
+	return reflect.Value{}, false
 }
@@ -6,13 +6,17 @@ import (
 	errors "github.com/kgip/go-spring/error"
 	"reflect"
 	"strings"
+	"unsafe"
 )
 
 const (
-	injectTag = "autowired"  //true, false
+	injectTag = "autowired"  //true, false, optional
 	configTag = "autoconfig" //true, false
 
-	beanNameTag = "name"
+	injectOptional = "optional" //依赖不存在时注入nil或零值
+
+	beanNameTag  = "name"
+	qualifierTag = "qualifier"
 
 	configPrefixTag          = "prefix"
 	configKeyTag             = "key"
@@ -39,6 +43,20 @@ type InstanceHandler interface {
 	Handle(c *Container, instance interface{})
 }
 
+// InstanceHandlerE 返回error的InstanceHandler,未实现时Handle中的panic会被转换为error
+type InstanceHandlerE interface {
+	InstanceHandler
+	HandleE(c *Container, instance interface{}) error
+}
+
+// handleInstance 调用实例处理器,兼容只实现了InstanceHandler的处理器
+func handleInstance(handler InstanceHandler, c *Container, instance interface{}) error {
+	if handlerE, ok := handler.(InstanceHandlerE); ok {
+		return handlerE.HandleE(c, instance)
+	}
+	return catch(func() { handler.Handle(c, instance) })
+}
+
 type ConfigInstanceHandler struct {
 	fieldHandler ConfigFieldHandler
 }
@@ -61,6 +79,12 @@ func (*ConfigInstanceHandler) IsSupport(instance interface{}) bool {
 }
 
 func (handler *ConfigInstanceHandler) Handle(c *Container, instance interface{}) {
+	if err := handler.HandleE(c, instance); err != nil {
+		panic(err)
+	}
+}
+
+func (handler *ConfigInstanceHandler) HandleE(c *Container, instance interface{}) error {
 	var prefix string
 	if store, ok := instance.(configuration.Storage); ok {
 		prefix = store.ConfigurationPrefix()
@@ -71,22 +95,39 @@ func (handler *ConfigInstanceHandler) Handle(c *Container, instance interface{})
 		if autoconfig, ok := f.Tag.Lookup(configTag); ok && autoconfig == "false" {
 			continue
 		}
-		handler.fieldHandler.Handle(c.GetConfiguration(), &f, prefix)
+		if err := handleConfigField(handler.fieldHandler, c.GetConfiguration(), &f, prefix); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 type ConfigFieldHandler interface {
 	Handle(c configuration.Provider, field *reflect.StructField, prefix string)
 }
 
+// ConfigFieldHandlerE 返回error的ConfigFieldHandler,未实现时Handle中的panic会被转换为error
+type ConfigFieldHandlerE interface {
+	ConfigFieldHandler
+	HandleE(c configuration.Provider, field *reflect.StructField, prefix string) error
+}
+
+// handleConfigField 调用配置字段处理器,兼容只实现了ConfigFieldHandler的处理器
+func handleConfigField(handler ConfigFieldHandler, c configuration.Provider, field *reflect.StructField, prefix string) error {
+	if handlerE, ok := handler.(ConfigFieldHandlerE); ok {
+		return handlerE.HandleE(c, field, prefix)
+	}
+	return catch(func() { handler.Handle(c, field, prefix) })
+}
+
 type DefaultConfigFieldHandler struct{}
 
-func (handler *DefaultConfigFieldHandler) resolveConfigKeySubKey(keyValueStr string, index int) (key, value string) {
+func (handler *DefaultConfigFieldHandler) resolveConfigKeySubKey(keyValueStr string, index int) (key, value string, err error) {
 	key = keyValueStr[:index]
 	if configKeySubKeys[key] {
 		value = keyValueStr[index+1:]
 	} else {
-		panic(errors.UnknownConfigKeySubKeyError.Detail(fmt.Sprintf("unknown sub key '%s'", key)))
+		err = errors.UnknownConfigKeySubKeyError.Detail(fmt.Sprintf("unknown sub key '%s'", key))
 	}
 	return
 }
@@ -94,7 +135,7 @@ func (handler *DefaultConfigFieldHandler) resolveConfigKeySubKey(keyValueStr str
 //configKey规则
 //1.只有单独一个key，无需添加value:前缀 `configKey:"path"`
 //2.多个key，需求添加子key前缀，多个key之间用空格隔开 `configKey:"value=path default=10.4.68.144:3306"`
-func (handler *DefaultConfigFieldHandler) resolveConfigKey(configKey string) map[string]string {
+func (handler *DefaultConfigFieldHandler) resolveConfigKey(configKey string) (map[string]string, error) {
 	splits := strings.Split(configKey, configKeySplitChar)
 	var keyValues []string
 	var keyValuesMap = map[string]string{}
@@ -107,7 +148,10 @@ func (handler *DefaultConfigFieldHandler) resolveConfigKey(configKey string) map
 	if len(keyValues) <= 0 {
 	} else if len(keyValues) == 1 {
 		if index := strings.Index(keyValues[0], configKeySubKeySplitChar); index > -1 {
-			k, v := handler.resolveConfigKeySubKey(keyValues[0], index)
+			k, v, err := handler.resolveConfigKeySubKey(keyValues[0], index)
+			if err != nil {
+				return nil, err
+			}
 			keyValuesMap[k] = v
 		} else {
 			keyValuesMap[configKeySubKeyValue] = keyValues[0]
@@ -115,25 +159,37 @@ func (handler *DefaultConfigFieldHandler) resolveConfigKey(configKey string) map
 	} else { //more than one sub key
 		for _, keyValue := range keyValues {
 			if index := strings.Index(keyValue, configKeySubKeySplitChar); index > -1 {
-				k, v := handler.resolveConfigKeySubKey(keyValue, index)
+				k, v, err := handler.resolveConfigKeySubKey(keyValue, index)
+				if err != nil {
+					return nil, err
+				}
 				keyValuesMap[k] = v
 			} else {
-				panic(errors.ConfigKeySubKeyResolveError.Detail(fmt.Sprintf("error key '%s'", keyValue)))
+				return nil, errors.ConfigKeySubKeyResolveError.Detail(fmt.Sprintf("error key '%s'", keyValue))
 			}
 		}
 	}
-	return keyValuesMap
+	return keyValuesMap, nil
 }
 
 func (handler *DefaultConfigFieldHandler) Handle(c configuration.Provider, field *reflect.StructField, prefix string) {
+	if err := handler.HandleE(c, field, prefix); err != nil {
+		panic(err)
+	}
+}
+
+func (handler *DefaultConfigFieldHandler) HandleE(c configuration.Provider, field *reflect.StructField, prefix string) error {
 	var configKey string
 	var defaultValue string
 	var configPrefix string
 	if key, ok := field.Tag.Lookup(configKeyTag); ok {
 		if key == "" {
-			panic(errors.ConfigKeyError.Detail(fmt.Sprintf("config key of '%s' can't be empty", field.Name)))
+			return errors.ConfigKeyError.Detail(fmt.Sprintf("config key of '%s' can't be empty", field.Name))
+		}
+		keyMap, err := handler.resolveConfigKey(key)
+		if err != nil {
+			return err
 		}
-		keyMap := handler.resolveConfigKey(key)
 		if keyMap[configKeySubKeyDefault] != "" {
 			defaultValue = keyMap[configKeySubKeyDefault]
 		}
@@ -154,11 +210,12 @@ func (handler *DefaultConfigFieldHandler) Handle(c configuration.Provider, field
 	fmt.Println(configPrefix, defaultValue)
 	if configKey != "" {
 		if value := c.GetConfig(configKey); value == nil {
-			panic(errors.UnknownConfigKeyError.Detail(configKey))
+			return errors.UnknownConfigKeyError.Detail(configKey)
 		} else {
 
 		}
 	}
+	return nil
 }
 
 type DefaultInstanceHandler struct{}
@@ -167,41 +224,31 @@ func (*DefaultInstanceHandler) IsSupport(instance interface{}) bool {
 	return true
 }
 
-func (*DefaultInstanceHandler) Handle(c *Container, instance interface{}) {
+func (handler *DefaultInstanceHandler) Handle(c *Container, instance interface{}) {
+	if err := handler.HandleE(c, instance); err != nil {
+		panic(err)
+	}
+}
+
+func (*DefaultInstanceHandler) HandleE(c *Container, instance interface{}) error {
 	rv := reflect.ValueOf(instance).Elem()
-	for i := 0; i < rv.NumField(); i++ {
-		f := rv.Type().Field(i)
-		//如果不自动注入，则跳过该field的赋值
-		if inject, ok := f.Tag.Lookup(injectTag); ok && inject == "false" {
+	for _, point := range fieldInjectionPoints(rv.Type()) {
+		field := rv.Field(point.index)
+		//已经赋值的field不再注入
+		if !field.IsZero() {
 			continue
 		}
-		var fieldInstance interface{}
-		var hasBeanNameTag bool
-		var tagName string
-		if tagName, hasBeanNameTag = f.Tag.Lookup(beanNameTag); hasBeanNameTag {
-			if fieldInstance = c.GetBeanInstanceByName(tagName); fieldInstance == nil {
-				panic(errors.UnknownBeanNameError.Detail(fmt.Sprintf("unknown bean name: %s", tagName)))
-			}
-		} else if !f.Anonymous {
-			fieldInstance = c.GetBeanInstanceByName(f.Name)
+		//未导出的字段通过地址赋值
+		if !field.CanSet() {
+			field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
 		}
-		if fieldInstance == nil {
-			fieldInstance = c.GetInstance(rv.Field(i).Type())
-		} else {
-			t := f.Type
-			fieldInstance = reflect.ValueOf(fieldInstance).Elem().Interface()
-			for t.Kind() != reflect.Ptr {
-				t = t.Elem()
-				fieldInstance = &fieldInstance
-			}
-			if t.Kind() != reflect.Struct && hasBeanNameTag {
-				panic(errors.TypeNotMatchError.Detail(fmt.Sprintf("field %s of instance %v is not a struct type", tagName, instance)))
-			}
-		}
-		if fieldInstance != nil {
-			rv.Set(reflect.ValueOf(fieldInstance))
+		value, err := c.resolveValue(point)
+		if err != nil {
+			return err
 		}
+		field.Set(value)
 	}
+	return nil
 }
 
 // AssignBeanPostProcessor 给bean赋值的后置处理器
@@ -210,7 +257,9 @@ type AssignBeanPostProcessor struct{}
 func (*AssignBeanPostProcessor) PostProcess(c *Container, instance interface{}) {
 	for _, handler := range instanceHandlers {
 		if handler.IsSupport(instance) {
-			handler.Handle(c, instance)
+			if err := handleInstance(handler, c, instance); err != nil {
+				panic(err)
+			}
 			return
 		}
 	}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"reflect"
+	"sort"
+)
+
+var eventPublisherType = reflect.TypeOf((*EventPublisher)(nil)).Elem()
+
+// EventPublisher 事件发布器,可以作为依赖注入,注入的是容器本身
+type EventPublisher interface {
+	Publish(event interface{}) error
+}
+
+// Listener 事件监听器,E为监听的事件类型,事件可以赋值给E时才会被处理,容器中实现了该接口的单例bean会被自动注册,
+// 并且在其他bean之前创建,以便收到其他bean的创建事件;监听器依赖的bean在监听器之前创建,其创建事件不会被收到
+type Listener[E any] interface {
+	OnEvent(event E) error
+}
+
+// AsyncListener 异步事件监听器,Async返回true时在新的goroutine中处理事件
+type AsyncListener interface {
+	Async() bool
+}
+
+// ContainerStartedEvent 容器初始化完成,容器后置处理器执行后发布
+type ContainerStartedEvent struct {
+	Container *Container
+}
+
+// ContainerClosedEvent 容器开始关闭,销毁bean之前发布
+type ContainerClosedEvent struct {
+	Container *Container
+}
+
+// BeanCreatedEvent 单例bean创建完成
+type BeanCreatedEvent struct {
+	Name     string
+	Instance interface{}
+}
+
+// ConfigChangedEvent 配置文件发生变化,配置提供者实现了configuration.ChangeNotifier时发布
+type ConfigChangedEvent struct {
+	Container *Container
+}
+
+// listener 已注册的事件监听器
+type listener struct {
+	instance  interface{}
+	method    reflect.Value
+	eventType reflect.Type
+	async     bool
+	priority  int
+}
+
+// isListenerType 根据类型判断bean是否为事件监听器,用于在实例化之前找出监听器bean
+func isListenerType(rt reflect.Type) bool {
+	if rt == nil {
+		return false
+	}
+	method, ok := rt.MethodByName("OnEvent")
+	if !ok {
+		return false
+	}
+	//非接口类型的方法第一个参数为接收者
+	in := 1
+	if rt.Kind() == reflect.Interface {
+		in = 0
+	}
+	return method.Type.NumIn() == in+1 && method.Type.NumOut() == 1 && method.Type.Out(0) == errorType
+}
+
+// listenersFirst 将单例的监听器bean排在其他bean之前,保持原有的相对顺序
+func listenersFirst(beans []*Bean) []*Bean {
+	ordered := make([]*Bean, 0, len(beans))
+	for _, bean := range beans {
+		if bean.isSingleton && isListenerType(bean.instanceType()) {
+			ordered = append(ordered, bean)
+		}
+	}
+	for _, bean := range beans {
+		if !bean.isSingleton || !isListenerType(bean.instanceType()) {
+			ordered = append(ordered, bean)
+		}
+	}
+	return ordered
+}
+
+// newListener 解析监听器的OnEvent方法,不是监听器时返回nil
+func newListener(instance interface{}) *listener {
+	if instance == nil {
+		return nil
+	}
+	method := reflect.ValueOf(instance).MethodByName("OnEvent")
+	if !method.IsValid() {
+		return nil
+	}
+	rt := method.Type()
+	if rt.NumIn() != 1 || rt.NumOut() != 1 || rt.Out(0) != errorType {
+		return nil
+	}
+	l := &listener{instance: instance, method: method, eventType: rt.In(0), priority: GetPriority(instance)}
+	if async, ok := instance.(AsyncListener); ok {
+		l.async = async.Async()
+	}
+	return l
+}
+
+// handle 调用监听器处理事件,监听器中的panic转换为error
+func (l *listener) handle(event interface{}) error {
+	var result error
+	if err := catch(func() {
+		if value := l.method.Call([]reflect.Value{reflect.ValueOf(event)})[0]; !value.IsNil() {
+			result = value.Interface().(error)
+		}
+	}); err != nil {
+		return err
+	}
+	return result
+}
+
+// AddListener 注册事件监听器,listener需要实现Listener接口,容器初始化之前注册可以收到所有bean的创建事件
+func (c *Container) AddListener(listener interface{}) {
+	if err := c.AddListenerE(listener); err != nil {
+		panic(err)
+	}
+}
+
+// AddListenerE 注册事件监听器,listener未实现Listener接口时返回错误
+func (c *Container) AddListenerE(listener interface{}) error {
+	l := newListener(listener)
+	if l == nil {
+		return errors.TypeNotMatchError.Detail(fmt.Sprintf("%T is not an event listener", listener))
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.listeners = append(c.listeners, l)
+	return nil
+}
+
+// Publish 发布事件,同步监听器按优先级从高到低依次处理,返回所有同步监听器的错误,异步监听器的错误只记录日志,
+// 容器关闭并发布ContainerClosedEvent之后不再发布事件
+func (c *Container) Publish(event interface{}) error {
+	if event == nil {
+		return errors.NilError
+	}
+	rt := reflect.TypeOf(event)
+	c.lock.Lock()
+	if c.eventsClosed {
+		c.lock.Unlock()
+		return errors.EventsClosedError.Detail(fmt.Sprintf("event %T dropped", event))
+	}
+	var matched []*listener
+	for _, l := range c.listeners {
+		if rt.AssignableTo(l.eventType) {
+			matched = append(matched, l)
+			//在检查关闭标记的锁内增加计数,保证waitEvents开始等待之后不再有新的异步监听器
+			if l.async {
+				c.events.Add(1)
+			}
+		}
+	}
+	c.lock.Unlock()
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].priority > matched[j].priority
+	})
+	var errs errors.MultiError
+	for _, l := range matched {
+		if l.async {
+			go func(l *listener) {
+				defer c.events.Done()
+				if err := l.handle(event); err != nil {
+					c.logger.Printf("async listener %T handle event %T failed: %v", l.instance, event, err)
+				}
+			}(l)
+			continue
+		}
+		if err := l.handle(event); err != nil {
+			errs = append(errs, fmt.Errorf("listener %T handle event %T failed: %w", l.instance, event, err))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// registerListener 创建完成的单例bean实现了Listener接口时自动注册
+func (c *Container) registerListener(bean *Bean, instance interface{}) {
+	if !bean.isSingleton {
+		return
+	}
+	if l := newListener(instance); l != nil {
+		c.lock.Lock()
+		c.listeners = append(c.listeners, l)
+		c.lock.Unlock()
+	}
+}
+
+// waitEvents 停止发布事件并等待异步监听器处理完成,ctx结束时返回超时错误
+func (c *Container) waitEvents(ctx context.Context) error {
+	c.lock.Lock()
+	c.eventsClosed = true
+	c.lock.Unlock()
+	done := make(chan struct{})
+	go func() {
+		c.events.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.DestroyTimeoutError.Detail("wait for async event listeners timeout")
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"reflect"
+)
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Get 按类型获取bean,规则与字段注入相同,不存在时返回NoSuchBeanError
+func Get[T any](c *Container) (T, error) {
+	var zero T
+	rt := typeOf[T]()
+	bean, err := c.resolveBean(&injectionPoint{rt: rt})
+	if err != nil {
+		return zero, err
+	}
+	if bean == nil {
+		return zero, errors.NoSuchBeanError.Detail(fmt.Sprintf("no bean of type %s", rt))
+	}
+	return getAs[T](c, bean)
+}
+
+// GetByName 按名称获取bean并转换为T类型
+func GetByName[T any](c *Container, name string) (T, error) {
+	var zero T
+	bean := c.lookup(name)
+	if err := c.ambiguousNameError(name); bean == nil && err != nil {
+		return zero, err
+	}
+	if bean == nil {
+		return zero, errors.UnknownBeanNameError.Detail(fmt.Sprintf("unknown bean name: %s", name))
+	}
+	return getAs[T](c, bean)
+}
+
+// GetAll 获取所有可以赋值给T类型的bean,按优先级从高到低排序
+func GetAll[T any](c *Container) ([]T, error) {
+	rt := typeOf[[]T]()
+	elem := collectionElem(rt)
+	if elem == nil {
+		return nil, errors.TypeNotMatchError.Detail(fmt.Sprintf("%s is not a struct, struct pointer or interface type", rt.Elem()))
+	}
+	value, err := c.resolveCollection(&injectionPoint{rt: rt}, elem)
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface().([]T), nil
+}
+
+func getAs[T any](c *Container, bean *Bean) (T, error) {
+	var zero T
+	instance, err := c.getBean(bean)
+	if err != nil {
+		return zero, err
+	}
+	if instance, err = c.proxyFor(bean, instance, typeOf[T]()); err != nil {
+		return zero, err
+	}
+	value, ok := convertInstance(instance, typeOf[T]())
+	if !ok {
+		return zero, errors.TypeNotMatchError.Detail(fmt.Sprintf("bean %s can't be converted to %s", bean.name, typeOf[T]()))
+	}
+	return value.Interface().(T), nil
+}
+
+// NewProviderBean 通过返回T类型的工厂方法创建bean,bean名称为T的类型名称
+func NewProviderBean[T any](factory func(c *Container) T) (*Bean, error) {
+	return NewFactoryBeanE(factory)
+}
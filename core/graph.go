@@ -0,0 +1,479 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kgip/go-spring/configuration"
+	errors "github.com/kgip/go-spring/error"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// injectionPoint 注入点,表示bean的一个待注入字段或工厂方法参数
+type injectionPoint struct {
+	name      string //字段名称,工厂方法参数为空
+	index     int    //字段或参数下标
+	isField   bool
+	anonymous bool
+	beanName  string //name标签指定的bean名称
+	qualifier string //qualifier标签指定的限定符,在多个候选bean中选择
+	optional  bool   //autowired:"optional",依赖不存在时注入nil或零值
+	rt        reflect.Type
+}
+
+func (point *injectionPoint) String() string {
+	if point.isField {
+		return "field " + point.name
+	}
+	return fmt.Sprintf("param %d", point.index)
+}
+
+// fieldInjectionPoints 获取结构体中需要注入的字段,跳过配置字段、autowired:"false"的字段以及没有autowired或name标签的未导出字段
+func fieldInjectionPoints(rt reflect.Type) []*injectionPoint {
+	if rt == nil {
+		return nil
+	}
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct || rt.Implements(reflect.TypeOf((*configuration.Storage)(nil)).Elem()) ||
+		reflect.PtrTo(rt).Implements(reflect.TypeOf((*configuration.Storage)(nil)).Elem()) {
+		return nil
+	}
+	var points []*injectionPoint
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if inject, ok := f.Tag.Lookup(injectTag); ok && inject == "false" || f.Type == componentType {
+			continue
+		}
+		if f.PkgPath != "" {
+			_, autowired := f.Tag.Lookup(injectTag)
+			_, named := f.Tag.Lookup(beanNameTag)
+			if !autowired && !named {
+				continue
+			}
+		}
+		if _, ok := f.Tag.Lookup(configPrefixTag); ok {
+			continue
+		}
+		if _, ok := f.Tag.Lookup(configKeyTag); ok {
+			continue
+		}
+		point := &injectionPoint{name: f.Name, index: i, isField: true, anonymous: f.Anonymous, rt: f.Type}
+		point.beanName, _ = f.Tag.Lookup(beanNameTag)
+		point.qualifier, _ = f.Tag.Lookup(qualifierTag)
+		point.optional = f.Tag.Get(injectTag) == injectOptional
+		points = append(points, point)
+	}
+	return points
+}
+
+// paramInjectionPoints 获取工厂方法需要注入的参数,容器指针参数不作为注入点
+func paramInjectionPoints(rt reflect.Type) []*injectionPoint {
+	var points []*injectionPoint
+	for i := 0; i < rt.NumIn(); i++ {
+		if isContainerType(rt.In(i)) {
+			continue
+		}
+		points = append(points, &injectionPoint{index: i, rt: rt.In(i)})
+	}
+	return points
+}
+
+func isContainerType(rt reflect.Type) bool {
+	return rt == eventPublisherType || rt.Kind() == reflect.Ptr && reflect.TypeOf(&Container{}).AssignableTo(rt)
+}
+
+// structBeanName 获取结构体类型对应的bean名称,优先使用BeanNameProvider
+func structBeanName(rt reflect.Type) string {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return ""
+	}
+	if provider, ok := reflect.New(rt).Interface().(BeanNameProvider); ok {
+		return provider.GetBeanName()
+	}
+	return rt.Name()
+}
+
+// assignable bean实例能否赋值给rt类型
+func assignable(bean *Bean, rt reflect.Type) bool {
+	instanceType := bean.instanceType()
+	if instanceType == nil {
+		return false
+	}
+	return instanceType.AssignableTo(rt) || instanceType.Kind() == reflect.Ptr && instanceType.Elem().AssignableTo(rt)
+}
+
+// collectionElem 获取集合注入点的元素类型,只支持[]T和map[string]T且T为结构体、结构体指针或非空接口,其余返回nil
+func collectionElem(rt reflect.Type) reflect.Type {
+	var elem reflect.Type
+	switch rt.Kind() {
+	case reflect.Slice:
+		elem = rt.Elem()
+	case reflect.Map:
+		if rt.Key().Kind() != reflect.String {
+			return nil
+		}
+		elem = rt.Elem()
+	default:
+		return nil
+	}
+	if isBeanType(elem) {
+		return elem
+	}
+	return nil
+}
+
+// resolveBeans 查找注入点依赖的所有bean,集合注入点返回所有匹配元素类型的bean,延迟注入点不产生依赖
+func (c *Container) resolveBeans(point *injectionPoint) ([]*Bean, error) {
+	if lazyTarget(point.rt) != nil {
+		return nil, nil
+	}
+	if point.beanName == "" {
+		if elem := collectionElem(point.rt); elem != nil {
+			return qualify(point.qualifier, c.candidates(elem)), nil
+		}
+	}
+	bean, err := c.resolveBean(point)
+	if err != nil || bean == nil {
+		return nil, err
+	}
+	return []*Bean{bean}, nil
+}
+
+// qualify 按限定符过滤候选bean,限定符为空时不过滤
+func qualify(qualifier string, candidates []*Bean) []*Bean {
+	if qualifier == "" {
+		return candidates
+	}
+	var qualified []*Bean
+	for _, bean := range candidates {
+		if bean.name == qualifier || bean.qualifier == qualifier || bean.hasAlias(qualifier) {
+			qualified = append(qualified, bean)
+		}
+	}
+	return qualified
+}
+
+// resolveBean 查找注入点对应的bean,不存在时返回nil;name标签指定的bean不存在或类型不匹配时返回错误
+func (c *Container) resolveBean(point *injectionPoint) (*Bean, error) {
+	if point.beanName == "" && isContainerType(point.rt) {
+		return nil, nil
+	}
+	if point.beanName != "" {
+		bean := c.lookup(point.beanName)
+		if bean == nil && point.optional {
+			return nil, nil
+		}
+		if err := c.ambiguousNameError(point.beanName); bean == nil && err != nil {
+			return nil, err
+		}
+		if bean == nil {
+			return nil, errors.UnknownBeanNameError.Detail(fmt.Sprintf("unknown bean name: %s", point.beanName))
+		}
+		if !assignable(bean, point.rt) {
+			return nil, errors.TypeNotMatchError.Detail(fmt.Sprintf("bean %s can't be assigned to %s of type %s", point.beanName, point, point.rt))
+		}
+		return bean, nil
+	}
+	if point.qualifier == "" {
+		if point.isField && !point.anonymous {
+			if bean := c.lookup(point.name); bean != nil && assignable(bean, point.rt) {
+				return bean, nil
+			}
+		}
+		if bean := c.typed(point.rt); bean != nil && assignable(bean, point.rt) {
+			return bean, nil
+		}
+	}
+	//按类型查找候选bean,空接口不参与查找
+	isInterface := point.rt.Kind() == reflect.Interface && point.rt.NumMethod() > 0
+	if !isInterface && structBeanName(point.rt) == "" {
+		return nil, nil
+	}
+	candidates := c.candidates(point.rt)
+	if len(candidates) == 0 && (point.optional || !isInterface && point.qualifier == "") {
+		return nil, nil
+	}
+	return selectCandidate(point, candidates)
+}
+
+// selectCandidate 在候选bean中选择一个,优先按限定符过滤,存在多个时选择primary bean
+func selectCandidate(point *injectionPoint, candidates []*Bean) (*Bean, error) {
+	if point.qualifier != "" {
+		qualified := qualify(point.qualifier, candidates)
+		if len(qualified) == 0 {
+			return nil, errors.NoSuchBeanError.Detail(fmt.Sprintf("no bean of type %s qualified by %s required by %s, candidates: %s", point.rt, point.qualifier, point, beanNamesOf(candidates)))
+		}
+		candidates = qualified
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, errors.NoSuchBeanError.Detail(fmt.Sprintf("no bean implements %s required by %s", point.rt, point))
+	case 1:
+		return candidates[0], nil
+	}
+	var primaries []*Bean
+	for _, bean := range candidates {
+		if bean.isPrimary {
+			primaries = append(primaries, bean)
+		}
+	}
+	if len(primaries) == 1 {
+		return primaries[0], nil
+	}
+	return nil, errors.AmbiguousBeanError.Detail(fmt.Sprintf("%s required by %s matches beans %s, mark one as primary or add a qualifier", point.rt, point, beanNamesOf(candidates)))
+}
+
+// candidates 获取实例可以赋值给rt类型的所有bean,按名称排序
+func (c *Container) candidates(rt reflect.Type) []*Bean {
+	var candidates []*Bean
+	for _, name := range c.beanNames() {
+		if bean := c.beans[name]; assignable(bean, rt) {
+			candidates = append(candidates, bean)
+		}
+	}
+	return candidates
+}
+
+func beanNamesOf(beans []*Bean) string {
+	names := make([]string, len(beans))
+	for i, bean := range beans {
+		names[i] = bean.name
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// unsatisfied 严格注入模式下,非optional的注入点找不到依赖时视为未满足,包括无法由容器提供的string、int等非bean类型,
+// bean集合和延迟注入点除外
+func (c *Container) unsatisfied(point *injectionPoint) bool {
+	return c.strictInjection && !point.optional && lazyTarget(point.rt) == nil && !isContainerType(point.rt) &&
+		(isBeanType(point.rt) || collectionElem(point.rt) == nil)
+}
+
+// dependency 依赖关系,表示from通过point依赖to
+type dependency struct {
+	from  *Bean
+	to    *Bean
+	point *injectionPoint
+}
+
+// buildGraph 根据所有bean的注入点构建依赖图
+func (c *Container) buildGraph() error {
+	c.graph = map[string][]*dependency{}
+	var errs errors.MultiError
+	for _, name := range c.beanNames() {
+		errs = append(errs, c.linkBean(c.beans[name])...)
+	}
+	return errs.ErrorOrNil()
+}
+
+// linkBean 解析bean的所有注入点,更新依赖图中该bean的依赖,返回无法解析的依赖
+func (c *Container) linkBean(bean *Bean) errors.MultiError {
+	var dependencies []*dependency
+	var errs errors.MultiError
+	for _, point := range bean.injectionPoints {
+		beans, err := c.resolveBeans(point)
+		if err != nil {
+			errs = append(errs, errors.WrapCreationError(bean.name, err))
+			continue
+		}
+		if len(beans) == 0 && c.unsatisfied(point) {
+			errs = append(errs, errors.WrapCreationError(bean.name, errors.NoSuchBeanError.Detail(
+				fmt.Sprintf("unsatisfied dependency %s of type %s", point, point.rt))))
+		}
+		for _, to := range beans {
+			dependencies = append(dependencies, &dependency{from: bean, to: to, point: point})
+		}
+	}
+	if dependencies == nil {
+		delete(c.graph, bean.name)
+	} else {
+		c.graph[bean.name] = dependencies
+	}
+	return errs
+}
+
+// updateGraph 注册bean后更新依赖图,重新解析新bean以及可能依赖新bean的注入点,有bean被覆盖或改名时重新构建
+func (c *Container) updateGraph(bean *Bean, rebuild bool) {
+	if rebuild {
+		_ = c.buildGraph()
+		return
+	}
+	c.linkBean(bean)
+	for _, name := range c.beanNames() {
+		if other := c.beans[name]; other != bean && c.mayDependOn(other, bean) {
+			c.linkBean(other)
+		}
+	}
+}
+
+// mayDependOn from的注入点是否可能解析到bean,按名称、限定符或类型匹配
+func (c *Container) mayDependOn(from *Bean, bean *Bean) bool {
+	names := append([]string{bean.name}, bean.aliases...)
+	for _, point := range from.injectionPoints {
+		if containsString(names, point.beanName) || containsString(names, point.name) || containsString(names, point.qualifier) {
+			return true
+		}
+		if assignable(bean, point.rt) {
+			return true
+		}
+		if elem := collectionElem(point.rt); elem != nil && assignable(bean, elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 根据当前注册的bean校验依赖图,返回无法解析的依赖和无法解决的循环依赖,不判断注册条件,不创建bean
+func (c *Container) Validate() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var errs errors.MultiError
+	if err := c.buildGraph(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.sortBeans(); err != nil {
+		errs = append(errs, err)
+	}
+	return errs.ErrorOrNil()
+}
+
+// sortBeans 对bean进行拓扑排序,被依赖的bean排在前面,存在循环依赖时返回完整的依赖链
+func (c *Container) sortBeans() ([]*Bean, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := map[string]int{}
+	var sorted []*Bean
+	var stack []*dependency
+	var errs errors.MultiError
+	var visit func(bean *Bean)
+	visit = func(bean *Bean) {
+		states[bean.name] = visiting
+		for _, dep := range c.graph[bean.name] {
+			stack = append(stack, dep)
+			switch states[dep.to.name] {
+			case unvisited:
+				visit(dep.to)
+			case visiting:
+				if cycle := cycleOf(stack, dep.to); c.strictCycles || !resolvableCycle(cycle) {
+					errs = append(errs, errors.CircularDependencyError.Detail(cycleChain(cycle)))
+				}
+			}
+			stack = stack[:len(stack)-1]
+		}
+		states[bean.name] = visited
+		sorted = append(sorted, bean)
+	}
+	for _, name := range c.beanNames() {
+		if states[name] == unvisited {
+			visit(c.beans[name])
+		}
+	}
+	return sorted, errs.ErrorOrNil()
+}
+
+// cycleOf 从依赖栈中截取以start开始的环
+func cycleOf(stack []*dependency, start *Bean) []*dependency {
+	i := len(stack) - 1
+	for i > 0 && stack[i].from != start {
+		i--
+	}
+	return stack[i:]
+}
+
+// resolvableCycle 环中的依赖均为单例bean之间的字段注入时,可以通过提前引用解决
+func resolvableCycle(cycle []*dependency) bool {
+	for _, dep := range cycle {
+		if !dep.point.isField || !dep.from.isSingleton || !dep.to.isSingleton {
+			return false
+		}
+	}
+	return true
+}
+
+// cycleChain 格式化依赖环,格式为 A(field B) -> B(param 0) -> A
+func cycleChain(cycle []*dependency) string {
+	var chain []string
+	for _, dep := range cycle {
+		chain = append(chain, fmt.Sprintf("%s(%s)", dep.from.name, dep.point))
+	}
+	return strings.Join(append(chain, cycle[0].from.name), " -> ")
+}
+
+func (c *Container) beanNames() []string {
+	names := make([]string, 0, len(c.beans))
+	for name := range c.beans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BeanGraph 已解析的bean依赖图,节点按名称排序,边按注入点顺序排序
+type BeanGraph struct {
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []*GraphEdge `json:"edges"`
+}
+
+// GraphNode 依赖图中的bean
+type GraphNode struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Scope    string `json:"scope"`
+	Priority int    `json:"priority"`
+}
+
+// GraphEdge 依赖图中的依赖关系,Label为字段或工厂方法参数
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label"`
+}
+
+// Graph 获取bean依赖图,注册bean时更新,初始化时判断注册条件后重新构建
+func (c *Container) Graph() *BeanGraph {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	graph := &BeanGraph{Nodes: []*GraphNode{}, Edges: []*GraphEdge{}}
+	for _, name := range c.beanNames() {
+		bean := c.beans[name]
+		node := &GraphNode{Name: name, Scope: bean.scope(), Priority: bean.priority}
+		if rt := bean.instanceType(); rt != nil {
+			node.Type = rt.String()
+		}
+		graph.Nodes = append(graph.Nodes, node)
+		for _, dep := range c.graph[name] {
+			graph.Edges = append(graph.Edges, &GraphEdge{From: name, To: dep.to.name, Label: dep.point.String()})
+		}
+	}
+	return graph
+}
+
+// DOT 将依赖图渲染为Graphviz DOT格式
+func (graph *BeanGraph) DOT() string {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph beans {\n")
+	for _, node := range graph.Nodes {
+		label := fmt.Sprintf("%s\n%s\n%s priority=%d", node.Name, node.Type, node.Scope, node.Priority)
+		fmt.Fprintf(builder, "\t%s [shape=box, label=%s];\n", strconv.Quote(node.Name), strconv.Quote(label))
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(builder, "\t%s -> %s [label=%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strconv.Quote(edge.Label))
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// JSON 将依赖图渲染为JSON格式
+func (graph *BeanGraph) JSON() ([]byte, error) {
+	return json.MarshalIndent(graph, "", "  ")
+}
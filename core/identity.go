@@ -0,0 +1,112 @@
+package core
+
+import (
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// typeKey bean的类型标识,指针取其元素类型,匿名类型返回nil
+func typeKey(bean *Bean) reflect.Type {
+	rt := bean.instanceType()
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Name() == "" {
+		return nil
+	}
+	return rt
+}
+
+// qualifiedName 包路径限定的类型名称,如github.com/kgip/go-spring/test/a.Init
+func qualifiedName(rt reflect.Type) string {
+	if rt.PkgPath() == "" {
+		return rt.Name()
+	}
+	return rt.PkgPath() + "." + rt.Name()
+}
+
+// qualifyName 默认名称与其他类型的bean冲突时,两者都改用包路径限定的名称,短名称标记为有歧义,返回已注册的bean是否被改名
+func (c *Container) qualifyName(bean *Bean) bool {
+	rt := typeKey(bean)
+	if !bean.implicitName || rt == nil {
+		return false
+	}
+	short := bean.name
+	if names, ok := c.ambiguous[short]; ok {
+		bean.name = qualifiedName(rt)
+		if !containsString(names, bean.name) {
+			c.ambiguous[short] = append(names, bean.name)
+		}
+		c.logger.Printf("bean short name %s is ambiguous between %s, bean:%s registered with its qualified name", short, strings.Join(c.ambiguous[short], ", "), bean.name)
+		return false
+	}
+	existing := c.beans[short]
+	if existing == nil || !existing.implicitName || typeKey(existing) == rt {
+		return false
+	}
+	c.renameBean(existing, qualifiedName(typeKey(existing)))
+	bean.name = qualifiedName(rt)
+	c.ambiguous[short] = []string{existing.name, bean.name}
+	c.logger.Printf("bean short name %s is ambiguous between %s, use the qualified names instead", short, strings.Join(c.ambiguous[short], ", "))
+	return true
+}
+
+// renameBean 修改已注册bean的名称,指向它的别名随之修改
+func (c *Container) renameBean(bean *Bean, name string) {
+	delete(c.beans, bean.name)
+	for alias, target := range c.aliases {
+		if target == bean.name {
+			c.aliases[alias] = name
+		}
+	}
+	bean.name = name
+	c.beans[name] = bean
+}
+
+// indexType 将以类型默认名称注册的bean加入类型索引
+func (c *Container) indexType(bean *Bean) {
+	if rt := typeKey(bean); rt != nil && (bean.implicitName || bean.name == structBeanName(rt)) {
+		c.types[rt] = bean
+	}
+}
+
+// unindexType 从类型索引中移除bean
+func (c *Container) unindexType(bean *Bean) {
+	if rt := typeKey(bean); rt != nil && c.types[rt] == bean {
+		delete(c.types, rt)
+	}
+}
+
+// typed 按结构体类型从类型索引中查找bean,rt可以是结构体或结构体指针
+func (c *Container) typed(rt reflect.Type) *Bean {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+	return c.types[rt]
+}
+
+// ambiguousNameError 短名称有歧义时返回错误,否则返回nil
+func (c *Container) ambiguousNameError(name string) error {
+	names, ok := c.ambiguous[name]
+	if !ok {
+		return nil
+	}
+	names = append([]string{}, names...)
+	sort.Strings(names)
+	return errors.AmbiguousBeanError.Detail(fmt.Sprintf("bean name %s is ambiguous, use one of the qualified names: [%s]", name, strings.Join(names, ", ")))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"reflect"
+)
+
+var (
+	lazyBinderType = reflect.TypeOf((*lazyBinder)(nil)).Elem()
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// lazyBinder 延迟注入的句柄,注入时绑定获取bean的方法
+type lazyBinder interface {
+	target() reflect.Type
+	bind(get func() (interface{}, error))
+}
+
+// Provider 延迟获取T类型的bean,每次调用Get时才从容器中获取,单例bean返回同一实例,原型bean每次返回新实例
+type Provider[T any] struct {
+	get func() (interface{}, error)
+}
+
+func (p *Provider[T]) target() reflect.Type {
+	return typeOf[T]()
+}
+
+func (p *Provider[T]) bind(get func() (interface{}, error)) {
+	p.get = get
+}
+
+// Get 从容器中获取bean
+func (p Provider[T]) Get() (T, error) {
+	var zero T
+	if p.get == nil {
+		return zero, errors.NilError.Detail(fmt.Sprintf("provider of %s is not injected", typeOf[T]()))
+	}
+	instance, err := p.get()
+	if err != nil {
+		return zero, err
+	}
+	return instance.(T), nil
+}
+
+// MustGet 从容器中获取bean,失败时panic
+func (p Provider[T]) MustGet() T {
+	instance, err := p.Get()
+	if err != nil {
+		panic(err)
+	}
+	return instance
+}
+
+func isBeanType(rt reflect.Type) bool {
+	return rt.Kind() == reflect.Interface && rt.NumMethod() > 0 || structBeanName(rt) != ""
+}
+
+// lazyTarget 获取延迟注入点的目标类型,支持func() T、func() (T, error)和Provider[T],非延迟注入点返回nil
+func lazyTarget(rt reflect.Type) reflect.Type {
+	if reflect.PtrTo(rt).Implements(lazyBinderType) {
+		return reflect.New(rt).Interface().(lazyBinder).target()
+	}
+	if rt.Kind() != reflect.Func || rt.NumIn() != 0 || rt.NumOut() == 0 || rt.NumOut() > 2 {
+		return nil
+	}
+	if rt.NumOut() == 2 && rt.Out(1) != errorType || !isBeanType(rt.Out(0)) {
+		return nil
+	}
+	return rt.Out(0)
+}
+
+// lazyValue 创建延迟注入点的值,调用时才按注入点的规则查找并获取bean
+func (c *Container) lazyValue(point *injectionPoint, target reflect.Type) reflect.Value {
+	targetPoint := *point
+	targetPoint.rt = target
+	get := func() (reflect.Value, error) {
+		bean, err := c.resolveBean(&targetPoint)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if bean == nil {
+			return reflect.Value{}, errors.NoSuchBeanError.Detail(fmt.Sprintf("no bean of type %s required by %s", target, point))
+		}
+		instance, err := c.getBean(bean)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if instance, err = c.proxyFor(bean, instance, target); err != nil {
+			return reflect.Value{}, err
+		}
+		value, _ := convertInstance(instance, target)
+		return value, nil
+	}
+	if point.rt.Kind() != reflect.Func {
+		provider := reflect.New(point.rt)
+		provider.Interface().(lazyBinder).bind(func() (interface{}, error) {
+			value, err := get()
+			if err != nil {
+				return nil, err
+			}
+			return value.Interface(), nil
+		})
+		return provider.Elem()
+	}
+	return reflect.MakeFunc(point.rt, func([]reflect.Value) []reflect.Value {
+		value, err := get()
+		if point.rt.NumOut() == 1 {
+			if err != nil {
+				panic(err)
+			}
+			return []reflect.Value{value}
+		}
+		if err != nil {
+			return []reflect.Value{reflect.Zero(target), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{value, reflect.Zero(errorType)}
+	})
+}
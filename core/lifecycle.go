@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"sort"
+)
+
+// Lifecycle 长时间运行的bean,如http服务、消息消费者、定时任务,容器实例化所有bean并执行容器后置处理器后调用Start,
+// 容器关闭时在销毁bean之前调用Stop
+type Lifecycle interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+	IsRunning() bool
+}
+
+// SmartLifecycle 带有阶段和自动启动标记的Lifecycle,阶段小的先启动后停止,依赖的bean总是先启动后停止,
+// IsAutoStartup返回false时容器不会自动启动,除非被自动启动的bean依赖
+type SmartLifecycle interface {
+	Lifecycle
+	GetPhase() int
+	IsAutoStartup() bool
+}
+
+// GetPhase 获取Lifecycle的阶段,未实现SmartLifecycle时为0
+func GetPhase(o interface{}) int {
+	if lifecycle, ok := o.(SmartLifecycle); ok {
+		return lifecycle.GetPhase()
+	}
+	return 0
+}
+
+// lifecycles 获取已创建的实现了Lifecycle的单例bean,按阶段从小到大排序,同一阶段按创建顺序排列,
+// 依赖的Lifecycle bean排在依赖它的bean之前,与阶段无关
+func (c *Container) lifecycles(singletons []*Bean) []*Bean {
+	var beans []*Bean
+	for _, bean := range singletons {
+		if _, ok := bean.instance.(Lifecycle); ok {
+			beans = append(beans, bean)
+		}
+	}
+	sort.SliceStable(beans, func(i, j int) bool {
+		return GetPhase(beans[i].instance) < GetPhase(beans[j].instance)
+	})
+	lifecycleBeans := make(map[*Bean]bool, len(beans))
+	for _, bean := range beans {
+		lifecycleBeans[bean] = true
+	}
+	visited := map[*Bean]bool{}
+	sorted := make([]*Bean, 0, len(beans))
+	var visit func(bean *Bean)
+	visit = func(bean *Bean) {
+		if visited[bean] {
+			return
+		}
+		visited[bean] = true
+		for _, dependency := range c.lifecycleDependencies(bean, lifecycleBeans) {
+			visit(dependency)
+		}
+		sorted = append(sorted, bean)
+	}
+	for _, bean := range beans {
+		visit(bean)
+	}
+	return sorted
+}
+
+// lifecycleDependencies 获取bean依赖的Lifecycle bean,经过非Lifecycle的bean间接依赖的也包括在内
+func (c *Container) lifecycleDependencies(bean *Bean, lifecycleBeans map[*Bean]bool) []*Bean {
+	var dependencies []*Bean
+	visited := map[*Bean]bool{bean: true}
+	var visit func(bean *Bean)
+	visit = func(bean *Bean) {
+		for _, dep := range c.graph[bean.name] {
+			if visited[dep.to] {
+				continue
+			}
+			visited[dep.to] = true
+			if lifecycleBeans[dep.to] {
+				dependencies = append(dependencies, dep.to)
+			} else {
+				visit(dep.to)
+			}
+		}
+	}
+	visit(bean)
+	return dependencies
+}
+
+// startLifecycles 按阶段启动自动启动的Lifecycle bean,启动前先启动其依赖的Lifecycle bean,启动失败时停止已经启动的bean并返回错误
+func (c *Container) startLifecycles(ctx context.Context) error {
+	c.lock.Lock()
+	beans := c.lifecycles(c.singletons)
+	c.lock.Unlock()
+	//自动启动的bean依赖的bean即使没有自动启动也需要启动
+	lifecycleBeans := make(map[*Bean]bool, len(beans))
+	for _, bean := range beans {
+		lifecycleBeans[bean] = true
+	}
+	required := map[*Bean]bool{}
+	for i := len(beans) - 1; i >= 0; i-- {
+		bean := beans[i]
+		if smart, ok := bean.instance.(SmartLifecycle); ok && !smart.IsAutoStartup() && !required[bean] {
+			continue
+		}
+		required[bean] = true
+		for _, dependency := range c.lifecycleDependencies(bean, lifecycleBeans) {
+			required[dependency] = true
+		}
+	}
+	for i, bean := range beans {
+		lifecycle := bean.instance.(Lifecycle)
+		if !required[bean] || lifecycle.IsRunning() {
+			continue
+		}
+		c.logger.Printf("start lifecycle bean:%s in phase %d", bean.name, GetPhase(lifecycle))
+		var err error
+		if recovered := catch(func() { err = lifecycle.Start(ctx) }); recovered != nil {
+			err = recovered
+		}
+		if err != nil {
+			c.stopLifecycles(ctx, beans[:i])
+			return errors.LifecycleStartError.Detail(fmt.Sprintf("bean %s: %v", bean.name, err))
+		}
+	}
+	return nil
+}
+
+// stopLifecycles 按启动顺序的逆序停止正在运行的Lifecycle bean,单个bean停止失败不影响其余bean
+func (c *Container) stopLifecycles(ctx context.Context, beans []*Bean) errors.MultiError {
+	var errs errors.MultiError
+	for i := len(beans) - 1; i >= 0; i-- {
+		bean := beans[i]
+		lifecycle := bean.instance.(Lifecycle)
+		if !lifecycle.IsRunning() {
+			continue
+		}
+		c.logger.Printf("stop lifecycle bean:%s in phase %d", bean.name, GetPhase(lifecycle))
+		var err error
+		if recovered := catch(func() { err = lifecycle.Stop(ctx) }); recovered != nil {
+			err = recovered
+		}
+		if err != nil {
+			err = errors.LifecycleStopError.Detail(fmt.Sprintf("bean %s: %v", bean.name, err))
+			c.logger.Println(err)
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
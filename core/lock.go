@@ -0,0 +1,46 @@
+package core
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// reentrantMutex 同一goroutine可以重复加锁的互斥锁,用于bean的创建,创建过程中的注入、初始化方法和后置处理器会递归获取bean
+type reentrantMutex struct {
+	mu    sync.Mutex
+	owner uint64 //持有锁的goroutine id,未加锁时为0
+	depth int
+}
+
+func (m *reentrantMutex) Lock() {
+	id := goroutineID()
+	if atomic.LoadUint64(&m.owner) == id {
+		m.depth++
+		return
+	}
+	m.mu.Lock()
+	atomic.StoreUint64(&m.owner, id)
+	m.depth = 1
+}
+
+func (m *reentrantMutex) Unlock() {
+	if m.depth--; m.depth == 0 {
+		atomic.StoreUint64(&m.owner, 0)
+		m.mu.Unlock()
+	}
+}
+
+// goroutineID 从调用栈的第一行"goroutine 1 [running]:"中解析当前goroutine的id
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+	return id
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"runtime"
+	"strings"
+)
+
+// OverridePolicy 同名bean的覆盖策略
+type OverridePolicy int
+
+const (
+	OverrideByPriority OverridePolicy = iota //优先级不低于已注册bean时覆盖,否则丢弃新bean,默认策略
+	OverrideForbidden                        //禁止覆盖,注册同名bean时报错
+	OverrideLastWins                         //后注册的bean总是覆盖先注册的bean
+)
+
+func (p OverridePolicy) String() string {
+	switch p {
+	case OverrideForbidden:
+		return "forbid"
+	case OverrideLastWins:
+		return "last-wins"
+	default:
+		return "allow-by-priority"
+	}
+}
+
+// BeanOverride 一次同名bean覆盖的记录
+type BeanOverride struct {
+	Name     string         //bean名称
+	Kept     string         //最终保留的bean的注册位置,file:line
+	Replaced string         //被覆盖或被丢弃的bean的注册位置,file:line
+	Policy   OverridePolicy //发生覆盖时的策略
+}
+
+func (o BeanOverride) String() string {
+	return fmt.Sprintf("bean:%s registered at %s overrides bean registered at %s (policy %s)", o.Name, o.Kept, o.Replaced, o.Policy)
+}
+
+// SetOverridePolicy 设置同名bean的覆盖策略
+func (c *Container) SetOverridePolicy(policy OverridePolicy) {
+	c.checkInited()
+	c.overridePolicy = policy
+}
+
+// Overrides 获取所有同名bean覆盖的记录,按发生顺序排列
+func (c *Container) Overrides() []BeanOverride {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]BeanOverride{}, c.overrides...)
+}
+
+// override 按覆盖策略判断新bean是否替换已注册的同名bean,并记录覆盖
+func (c *Container) override(existing *Bean, bean *Bean) (bool, error) {
+	replace := true
+	switch c.overridePolicy {
+	case OverrideForbidden:
+		return false, errors.BeanOverrideError.Detail(fmt.Sprintf("bean %s registered at %s is already registered at %s", bean.name, bean.source, existing.source))
+	case OverrideByPriority:
+		replace = GetPriority(existing) <= GetPriority(bean)
+	}
+	record := BeanOverride{Name: bean.name, Kept: bean.source, Replaced: existing.source, Policy: c.overridePolicy}
+	if !replace {
+		record.Kept, record.Replaced = existing.source, bean.source
+	}
+	c.overrides = append(c.overrides, record)
+	c.logger.Print(record)
+	return replace, nil
+}
+
+// registrationSite 获取框架外部调用方的位置,file:line
+func registrationSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !isFrameworkFrame(frame.Function) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+func isFrameworkFrame(function string) bool {
+	for _, pkg := range []string{"github.com/kgip/go-spring/core.", "github.com/kgip/go-spring/ioc."} {
+		if strings.HasPrefix(function, pkg) {
+			return true
+		}
+	}
+	return false
+}
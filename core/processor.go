@@ -1,5 +1,7 @@
 package core
 
+import "context"
+
 // ContainerPreProcessor 容器前置处理器
 type ContainerPreProcessor interface {
 	PreProcess(c *Container)
@@ -15,11 +17,29 @@ type Initializer interface {
 	Init(c *Container)
 }
 
+// Destroyer bean销毁器,容器关闭时调用,实现io.Closer的bean同样会被关闭
+type Destroyer interface {
+	Destroy(ctx context.Context) error
+}
+
 // BeanPostProcessor bean的后置处理器,bean初始化后执行
 type BeanPostProcessor interface {
 	PostProcess(c *Container, instance interface{})
 }
 
+// BeanReplacePostProcessor 可以替换bean实例的后置处理器,PostProcess之后调用,返回值作为最终的bean实例,如代理对象
+type BeanReplacePostProcessor interface {
+	BeanPostProcessor
+	Replace(c *Container, bean *Bean, instance interface{}) (interface{}, error)
+}
+
+// EarlyReferencePostProcessor 可以替换提前引用的后置处理器,单例bean因循环依赖被提前引用时调用EarlyReference,
+// 返回值作为提前引用注入到其他bean中;已经被提前引用的bean不再调用该处理器的Replace,最终的bean实例即为提前引用
+type EarlyReferencePostProcessor interface {
+	BeanReplacePostProcessor
+	EarlyReference(c *Container, bean *Bean, instance interface{}) (interface{}, error)
+}
+
 // ContainerPostProcessor 容器后置处理器
 type ContainerPostProcessor interface {
 	PostProcess(c *Container)
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	errors "github.com/kgip/go-spring/error"
+	"reflect"
+	"sync"
+)
+
+var (
+	interceptorType = reflect.TypeOf((*Interceptor)(nil)).Elem()
+	proxies         = map[reflect.Type]func(target interface{}, interceptors []Interceptor) interface{}{}
+	proxiesLock     = &sync.RWMutex{}
+)
+
+// Method 被拦截的方法调用,拦截器通过Proceed调用拦截链中的下一个拦截器,最后调用目标方法
+type Method struct {
+	Interface reflect.Type //代理的接口类型
+	Name      string       //方法名称
+	Target    interface{}  //被代理的bean实例
+	next      func(ctx context.Context, args []interface{}) []interface{}
+}
+
+// Proceed 调用拦截链中的下一个拦截器,返回目标方法的返回值
+func (m *Method) Proceed(ctx context.Context, args []interface{}) []interface{} {
+	return m.next(ctx, args)
+}
+
+func (m *Method) String() string {
+	return fmt.Sprintf("%s.%s", m.Interface, m.Name)
+}
+
+// Interceptor 方法拦截器,Invoke中调用method.Proceed继续执行,可以修改参数和返回值,不调用Proceed时直接返回
+type Interceptor interface {
+	Invoke(ctx context.Context, method *Method, args []interface{}) []interface{}
+}
+
+// InterceptorFunc 函数形式的方法拦截器
+type InterceptorFunc func(ctx context.Context, method *Method, args []interface{}) []interface{}
+
+func (f InterceptorFunc) Invoke(ctx context.Context, method *Method, args []interface{}) []interface{} {
+	return f(ctx, method, args)
+}
+
+// RegisterProxy 注册接口T的代理工厂,通常由代码生成器生成的代码在init中调用
+func RegisterProxy[T any](factory func(target T, interceptors []Interceptor) T) {
+	rt := typeOf[T]()
+	if rt.Kind() != reflect.Interface {
+		panic(errors.TypeNotMatchError.Detail(fmt.Sprintf("proxy type %s is not an interface", rt)))
+	}
+	proxiesLock.Lock()
+	defer proxiesLock.Unlock()
+	proxies[rt] = func(target interface{}, interceptors []Interceptor) interface{} {
+		return factory(target.(T), interceptors)
+	}
+}
+
+func proxyFactory(rt reflect.Type) func(target interface{}, interceptors []Interceptor) interface{} {
+	proxiesLock.RLock()
+	defer proxiesLock.RUnlock()
+	return proxies[rt]
+}
+
+// InvokeChain 按顺序执行拦截链,最后调用invoke执行目标方法,由生成的代理方法调用
+func InvokeChain(ctx context.Context, method *Method, interceptors []Interceptor, args []interface{}, invoke func(args []interface{}) []interface{}) []interface{} {
+	var next func(i int) func(ctx context.Context, args []interface{}) []interface{}
+	next = func(i int) func(ctx context.Context, args []interface{}) []interface{} {
+		return func(ctx context.Context, args []interface{}) []interface{} {
+			if i == len(interceptors) {
+				return invoke(args)
+			}
+			current := *method
+			current.next = next(i + 1)
+			return interceptors[i].Invoke(ctx, &current, args)
+		}
+	}
+	return next(0)(ctx, args)
+}
+
+// ProxyBeanPostProcessor 将以接口类型暴露的bean替换为注册的代理对象,容器中实现了Interceptor的bean按优先级从高到低组成拦截链,
+// 结构体bean在按注册了代理的接口注入时注入代理对象
+type ProxyBeanPostProcessor struct{}
+
+func (p *ProxyBeanPostProcessor) PostProcess(c *Container, instance interface{}) {}
+
+func (p *ProxyBeanPostProcessor) Replace(c *Container, bean *Bean, instance interface{}) (interface{}, error) {
+	rt := bean.instanceType()
+	if rt == nil || rt.Kind() != reflect.Interface || rt == interceptorType {
+		return instance, nil
+	}
+	factory := proxyFactory(rt)
+	if factory == nil {
+		return instance, nil
+	}
+	interceptors, err := c.interceptors()
+	if err != nil || len(interceptors) == 0 {
+		return instance, err
+	}
+	c.logger.Printf("bean:%s proxied by %s with %d interceptors", bean.name, rt, len(interceptors))
+	return factory(instance, interceptors), nil
+}
+
+// EarlyReference 循环依赖中被提前引用的bean同样注入代理对象
+func (p *ProxyBeanPostProcessor) EarlyReference(c *Container, bean *Bean, instance interface{}) (interface{}, error) {
+	return p.Replace(c, bean, instance)
+}
+
+// proxyFor 结构体bean按注册了代理的接口rt注入时返回代理对象,单例bean的代理按接口缓存
+func (c *Container) proxyFor(bean *Bean, instance interface{}, rt reflect.Type) (interface{}, error) {
+	declared := bean.instanceType()
+	if rt.Kind() != reflect.Interface || rt == interceptorType || declared == nil || declared.Kind() == reflect.Interface || !bean.isProxied() {
+		return instance, nil
+	}
+	factory := proxyFactory(rt)
+	if factory == nil || instance == nil || !reflect.TypeOf(instance).Implements(rt) {
+		return instance, nil
+	}
+	c.lock.Lock()
+	proxy, ok := c.proxyInstances[bean.name][rt]
+	c.lock.Unlock()
+	if ok && bean.isSingleton {
+		return proxy, nil
+	}
+	interceptors, err := c.interceptors()
+	if err != nil || len(interceptors) == 0 {
+		return instance, err
+	}
+	proxy = factory(instance, interceptors)
+	if bean.isSingleton {
+		c.lock.Lock()
+		if c.proxyInstances[bean.name] == nil {
+			c.proxyInstances[bean.name] = map[reflect.Type]interface{}{}
+		}
+		c.proxyInstances[bean.name][rt] = proxy
+		c.lock.Unlock()
+	}
+	return proxy, nil
+}
+
+// isProxied bean是否由ProxyBeanPostProcessor处理
+func (bean *Bean) isProxied() bool {
+	for _, processor := range bean.beanPostProcessors {
+		if _, ok := processor.(*ProxyBeanPostProcessor); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// interceptors 获取容器中所有的拦截器bean,与切片注入相同按优先级从高到低排序
+func (c *Container) interceptors() ([]Interceptor, error) {
+	value, err := c.resolveCollection(&injectionPoint{rt: reflect.SliceOf(interceptorType)}, interceptorType)
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface().([]Interceptor), nil
+}
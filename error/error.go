@@ -2,6 +2,7 @@ package error
 
 import (
 	"fmt"
+	"strings"
 )
 
 type IocError struct {
@@ -13,8 +14,65 @@ func (e *IocError) Error() string {
 	return fmt.Sprintf(`{"message": "%s", "detail": "%s"}`, e.message, e.detail)
 }
 
-func (e IocError) Detail(detail string) IocError {
+// Is 同一类错误的不同detail视为相同错误,支持errors.Is判断错误类型
+func (e *IocError) Is(target error) bool {
+	if t, ok := target.(*IocError); ok {
+		return e.message == t.message
+	}
+	return false
+}
+
+func (e IocError) Detail(detail string) *IocError {
 	e.detail = detail
+	return &e
+}
+
+// BeanCreationError bean创建失败,Path为从最外层bean到失败bean的创建路径
+type BeanCreationError struct {
+	Path []string
+	Err  error
+}
+
+func (e *BeanCreationError) Error() string {
+	return fmt.Sprintf("create bean %s failed, creation path: %s, cause: %v", e.Path[len(e.Path)-1], strings.Join(e.Path, " -> "), e.Err)
+}
+
+func (e *BeanCreationError) Unwrap() error {
+	return e.Err
+}
+
+// WrapCreationError 将bean名称加入创建路径的最前面,err不是BeanCreationError时创建新的BeanCreationError
+func WrapCreationError(name string, err error) *BeanCreationError {
+	if creationErr, ok := err.(*BeanCreationError); ok {
+		return &BeanCreationError{Path: append([]string{name}, creationErr.Path...), Err: creationErr.Err}
+	}
+	return &BeanCreationError{Path: []string{name}, Err: err}
+}
+
+// Recover 将recover得到的值转换为error
+func Recover(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
+
+// MultiError 多个错误的聚合,单个错误不会中断其余流程
+type MultiError []error
+
+func (e MultiError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors occurred:\n\t%s", len(e), strings.Join(messages, "\n\t"))
+}
+
+// ErrorOrNil 没有错误时返回nil
+func (e MultiError) ErrorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
 	return e
 }
 
@@ -31,4 +89,21 @@ var (
 	UnknownConfigKeyError       = &IocError{message: "unknown config key"}
 	UnknownConfigKeySubKeyError = &IocError{message: "unknown config key sub key"}
 	ConfigKeySubKeyResolveError = &IocError{message: "config key sub key resolve failed"}
+	DestroyBeanError            = &IocError{message: "Bean destroy failed"}
+	DestroyTimeoutError         = &IocError{message: "Bean destroy timeout"}
+	ConfigLoadError             = &IocError{message: "Load configuration failed"}
+	ProcessorError              = &IocError{message: "Container processor failed"}
+	CircularDependencyError     = &IocError{message: "Circular dependency between beans"}
+	UnknownGraphFormatError     = &IocError{message: "Unknown bean graph format"}
+	NoSuchBeanError             = &IocError{message: "No bean matches the required type"}
+	AmbiguousBeanError          = &IocError{message: "More than one bean matches the required type"}
+	UnknownModuleError          = &IocError{message: "Unknown module"}
+	DuplicateModuleError        = &IocError{message: "Module already installed"}
+	AliasCollisionError         = &IocError{message: "Bean alias collides with another bean"}
+	BeanOverrideError           = &IocError{message: "Bean override is forbidden"}
+	ComponentTagError           = &IocError{message: "Invalid component bean tag"}
+	EventsClosedError           = &IocError{message: "Container is shut down and no longer publishes events"}
+	EarlyReferenceError         = &IocError{message: "Bean was injected as an early reference but replaced afterwards"}
+	LifecycleStartError         = &IocError{message: "Lifecycle bean start failed"}
+	LifecycleStopError          = &IocError{message: "Lifecycle bean stop failed"}
 )
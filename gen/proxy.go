@@ -0,0 +1,293 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProxyMarker = "go-spring:proxy"       //接口注释中包含该标记时生成代理
+	ProxyFile   = "zz_generated_proxy.go" //生成的代理文件名
+	corePackage = "github.com/kgip/go-spring/core"
+)
+
+// proxyInterface 需要生成代理的接口
+type proxyInterface struct {
+	name    string
+	methods []*proxyMethod
+}
+
+// proxyMethod 接口方法,参数和返回值类型为源码中的类型表达式
+type proxyMethod struct {
+	name     string
+	params   []string
+	results  []string
+	variadic bool
+}
+
+// GenerateProxies 为dir目录下注释中带有go-spring:proxy标记的接口生成代理,写入zz_generated_proxy.go,没有标记的接口时删除该文件
+func GenerateProxies(dir string) error {
+	source, err := Proxies(dir)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(dir, ProxyFile)
+	if source == nil {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(file, source, 0644)
+}
+
+// Proxies 生成dir目录下带有标记的接口的代理源码,没有标记的接口时返回nil
+func Proxies(dir string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != ProxyFile
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected exactly one package in %s, found %d", dir, len(pkgs))
+	}
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+	files := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	var interfaces []*proxyInterface
+	//导入路径到生成文件中使用的包名,代理代码用到的包预先占用包名
+	imports := map[string]string{"context": "context", "reflect": "reflect", corePackage: "core"}
+	for _, name := range files {
+		found, err := fileProxies(fset, pkg.Files[name], imports)
+		if err != nil {
+			return nil, err
+		}
+		interfaces = append(interfaces, found...)
+	}
+	if len(interfaces) == 0 {
+		return nil, nil
+	}
+	return renderProxies(pkg.Name, interfaces, imports)
+}
+
+// fileProxies 解析文件中带有标记的接口,并记录方法签名中用到的导入
+func fileProxies(fset *token.FileSet, file *ast.File, imports map[string]string) ([]*proxyInterface, error) {
+	fileImports := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := packageName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		fileImports[name] = importPath
+	}
+	var interfaces []*proxyInterface
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			iface, ok := typeSpec.Type.(*ast.InterfaceType)
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			if !ok || doc == nil || !strings.Contains(doc.Text(), ProxyMarker) {
+				continue
+			}
+			if typeSpec.TypeParams != nil {
+				return nil, fmt.Errorf("%s: generic interface %s can't be proxied", fset.Position(typeSpec.Pos()), typeSpec.Name.Name)
+			}
+			proxy := &proxyInterface{name: typeSpec.Name.Name}
+			for _, field := range iface.Methods.List {
+				funcType, ok := field.Type.(*ast.FuncType)
+				if !ok {
+					return nil, fmt.Errorf("%s: embedded interface in %s is not supported, declare the methods explicitly", fset.Position(field.Pos()), proxy.name)
+				}
+				//包名改写为生成文件中的包名后再输出类型表达式
+				ast.Inspect(funcType, func(node ast.Node) bool {
+					if selector, ok := node.(*ast.SelectorExpr); ok {
+						if ident, ok := selector.X.(*ast.Ident); ok && fileImports[ident.Name] != "" {
+							ident.Name = importName(imports, fileImports[ident.Name], ident.Name)
+						}
+					}
+					return true
+				})
+				method := &proxyMethod{name: field.Names[0].Name}
+				method.params, method.variadic = fieldTypes(fset, funcType.Params)
+				method.results, _ = fieldTypes(fset, funcType.Results)
+				proxy.methods = append(proxy.methods, method)
+			}
+			interfaces = append(interfaces, proxy)
+		}
+	}
+	return interfaces, nil
+}
+
+// importName 返回导入路径在生成文件中使用的包名,包名已被其他导入路径占用时加数字后缀作为别名
+func importName(imports map[string]string, importPath, name string) string {
+	if existing, ok := imports[importPath]; ok {
+		return existing
+	}
+	used := make(map[string]bool, len(imports))
+	for _, n := range imports {
+		used[n] = true
+	}
+	alias := name
+	for i := 2; used[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	imports[importPath] = alias
+	return alias
+}
+
+// fieldTypes 展开参数列表中的类型,返回是否为可变参数
+func fieldTypes(fset *token.FileSet, fields *ast.FieldList) ([]string, bool) {
+	if fields == nil {
+		return nil, false
+	}
+	var types []string
+	var variadic bool
+	for _, field := range fields.List {
+		expr := field.Type
+		if ellipsis, ok := expr.(*ast.Ellipsis); ok {
+			expr, variadic = ellipsis.Elt, true
+		}
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, fset, expr)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			types = append(types, buf.String())
+		}
+	}
+	return types, variadic
+}
+
+// packageName 根据导入路径推断包名,忽略末尾的版本号
+func packageName(importPath string) string {
+	name := path.Base(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func renderProxies(pkgName string, interfaces []*proxyInterface, imports map[string]string) ([]byte, error) {
+	paths := make([]string, 0, len(imports))
+	for importPath := range imports {
+		paths = append(paths, importPath)
+	}
+	sort.Strings(paths)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by go-spring proxy. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	for _, importPath := range paths {
+		if name := imports[importPath]; name == packageName(importPath) {
+			fmt.Fprintf(&buf, "\t%q\n", importPath)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", name, importPath)
+		}
+	}
+	buf.WriteString(")\n\nfunc init() {\n")
+	for _, iface := range interfaces {
+		fmt.Fprintf(&buf, "\tcore.RegisterProxy[%s](func(target %s, interceptors []core.Interceptor) %s {\n", iface.name, iface.name, iface.name)
+		fmt.Fprintf(&buf, "\t\treturn &%s{target: target, interceptors: interceptors}\n\t})\n", proxyTypeName(iface.name))
+	}
+	buf.WriteString("}\n")
+	for _, iface := range interfaces {
+		renderProxy(&buf, iface)
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated proxies: %w", err)
+	}
+	return source, nil
+}
+
+func proxyTypeName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:] + "Proxy"
+}
+
+func renderProxy(buf *bytes.Buffer, iface *proxyInterface) {
+	proxyType := proxyTypeName(iface.name)
+	fmt.Fprintf(buf, "\n// %s 接口%s的代理,方法调用经过拦截链后再调用目标bean\ntype %s struct {\n\ttarget %s\n\tinterceptors []core.Interceptor\n}\n",
+		proxyType, iface.name, proxyType, iface.name)
+	for _, method := range iface.methods {
+		params := make([]string, len(method.params))
+		args := make([]string, len(method.params))
+		unpacked := make([]string, len(method.params))
+		for i, paramType := range method.params {
+			variadic := method.variadic && i == len(method.params)-1
+			args[i] = fmt.Sprintf("p%d", i)
+			unpacked[i] = fmt.Sprintf("a%d", i)
+			if variadic {
+				params[i] = fmt.Sprintf("p%d ...%s", i, paramType)
+				unpacked[i] += "..."
+			} else {
+				params[i] = fmt.Sprintf("p%d %s", i, paramType)
+			}
+		}
+		results := make([]string, len(method.results))
+		for i := range method.results {
+			results[i] = fmt.Sprintf("r%d", i)
+		}
+		ctx := "context.Background()"
+		if len(method.params) > 0 && method.params[0] == "context.Context" {
+			ctx = "p0"
+		}
+		signature := strings.Join(params, ", ") + ")"
+		if len(method.results) > 0 {
+			signature += " (" + strings.Join(method.results, ", ") + ")"
+		}
+		fmt.Fprintf(buf, "\nfunc (proxy *%s) %s(%s {\n", proxyType, method.name, signature)
+		fmt.Fprintf(buf, "\tmethod := &core.Method{Interface: reflect.TypeOf((*%s)(nil)).Elem(), Name: %q, Target: proxy.target}\n", iface.name, method.name)
+		call := "\t"
+		if len(results) > 0 {
+			call = "\tresults := "
+		}
+		fmt.Fprintf(buf, "%score.InvokeChain(%s, method, proxy.interceptors, []interface{}{%s}, func(args []interface{}) []interface{} {\n", call, ctx, strings.Join(args, ", "))
+		for i, paramType := range method.params {
+			if method.variadic && i == len(method.params)-1 {
+				paramType = "[]" + paramType
+			}
+			fmt.Fprintf(buf, "\t\ta%d, _ := args[%d].(%s)\n", i, i, paramType)
+		}
+		if len(results) > 0 {
+			fmt.Fprintf(buf, "\t\t%s := proxy.target.%s(%s)\n", strings.Join(results, ", "), method.name, strings.Join(unpacked, ", "))
+			fmt.Fprintf(buf, "\t\treturn []interface{}{%s}\n\t})\n", strings.Join(results, ", "))
+		} else {
+			fmt.Fprintf(buf, "\t\tproxy.target.%s(%s)\n\t\treturn nil\n\t})\n", method.name, strings.Join(unpacked, ", "))
+		}
+		//拦截器可以不调用Proceed直接返回,返回值不足时使用零值
+		for i, resultType := range method.results {
+			fmt.Fprintf(buf, "\tvar r%d %s\n\tif len(results) > %d {\n\t\tr%d, _ = results[%d].(%s)\n\t}\n", i, resultType, i, i, i, resultType)
+		}
+		if len(results) > 0 {
+			fmt.Fprintf(buf, "\treturn %s\n", strings.Join(results, ", "))
+		}
+		buf.WriteString("}\n")
+	}
+}
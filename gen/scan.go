@@ -0,0 +1,248 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kgip/go-spring/core"
+)
+
+const (
+	BeanMarker = "go-spring:bean"        //工厂函数注释中包含该标记时注册为bean,标记后为bean标签选项
+	BeansFile  = "zz_generated_beans.go" //生成的注册文件名
+	iocPackage = "github.com/kgip/go-spring/ioc"
+)
+
+// component 扫描到的组件,结构体或工厂函数
+type component struct {
+	name    string
+	factory bool
+	options *core.ComponentOptions
+}
+
+// GenerateBeans 扫描patterns对应的目录,dir/...表示递归扫描子目录,为嵌入core.Component的结构体和带有go-spring:bean标记的工厂函数
+// 生成注册bean的zz_generated_beans.go,其中RegisterBeans向指定应用注册,init向默认应用注册,没有组件的目录删除该文件
+func GenerateBeans(patterns ...string) error {
+	dirs, err := scanDirs(patterns)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		source, err := Components(dir)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(dir, BeansFile)
+		if source == nil {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(file, source, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanDirs 展开dir/...为包含go文件的所有子目录,跳过testdata、vendor以及以.或_开头的目录
+func scanDirs(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		if !strings.HasSuffix(pattern, "...") {
+			dirs = append(dirs, pattern)
+			continue
+		}
+		root := filepath.Clean(strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/"))
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return err
+			}
+			name := info.Name()
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if path != root {
+				if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			if matches, _ := filepath.Glob(filepath.Join(path, "*.go")); len(matches) > 0 {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// Components 生成dir目录下组件的注册源码,没有组件时返回nil
+func Components(dir string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != BeansFile
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected exactly one package in %s, found %d", dir, len(pkgs))
+	}
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+	files := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	var components []*component
+	for _, name := range files {
+		found, err := fileComponents(fset, pkg.Files[name])
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, found...)
+	}
+	if len(components) == 0 {
+		return nil, nil
+	}
+	return renderComponents(pkg.Name, components)
+}
+
+// fileComponents 查找文件中嵌入core.Component的结构体和带有标记的工厂函数
+func fileComponents(fset *token.FileSet, file *ast.File) ([]*component, error) {
+	coreName := ""
+	for _, spec := range file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath == corePackage {
+			coreName = packageName(importPath)
+			if spec.Name != nil {
+				coreName = spec.Name.Name
+			}
+		}
+	}
+	var components []*component
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE || coreName == "" {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				st, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				field := componentField(st, coreName)
+				if field == nil {
+					continue
+				}
+				if typeSpec.TypeParams != nil {
+					return nil, fmt.Errorf("%s: generic component %s can't be registered", fset.Position(typeSpec.Pos()), typeSpec.Name.Name)
+				}
+				var tag string
+				if field.Tag != nil {
+					tag, _ = strconv.Unquote(field.Tag.Value)
+				}
+				options, err := core.ParseComponentTag(reflect.StructTag(tag).Get("bean"))
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", fset.Position(field.Pos()), err)
+				}
+				components = append(components, &component{name: typeSpec.Name.Name, options: options})
+			}
+		case *ast.FuncDecl:
+			tag, ok := beanDirective(decl.Doc)
+			if !ok {
+				continue
+			}
+			if decl.Recv != nil || decl.Type.TypeParams != nil {
+				return nil, fmt.Errorf("%s: %s marks %s, only package level non-generic functions can be factory beans", fset.Position(decl.Pos()), BeanMarker, decl.Name.Name)
+			}
+			options, err := core.ParseComponentTag(tag)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fset.Position(decl.Pos()), err)
+			}
+			components = append(components, &component{name: decl.Name.Name, factory: true, options: options})
+		}
+	}
+	return components, nil
+}
+
+// componentField 获取嵌入的core.Component字段
+func componentField(st *ast.StructType, coreName string) *ast.Field {
+	for _, field := range st.Fields.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		if selector, ok := field.Type.(*ast.SelectorExpr); ok && selector.Sel.Name == "Component" {
+			if ident, ok := selector.X.(*ast.Ident); ok && ident.Name == coreName {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+// beanDirective 获取注释中go-spring:bean标记后的标签选项
+func beanDirective(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, BeanMarker) {
+			return strings.TrimSpace(strings.TrimPrefix(line, BeanMarker)), true
+		}
+	}
+	return "", false
+}
+
+func renderComponents(pkgName string, components []*component) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by go-spring scan. DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+	fmt.Fprintf(&buf, "import (\n\t%q\n\t%q\n)\n\n", corePackage, iocPackage)
+	buf.WriteString("func init() {\n\tRegisterBeans(ioc.GetApplication())\n}\n\n")
+	buf.WriteString("// RegisterBeans 向app注册包中扫描到的组件\nfunc RegisterBeans(app *ioc.Application) {\n\tapp.RegisterBeans(\n")
+	for _, c := range components {
+		//结构体的bean标签在core.NewBean中解析,工厂函数的选项需要显式设置
+		if !c.factory {
+			fmt.Fprintf(&buf, "\t\tcore.NewBean(&%s{}),\n", c.name)
+			continue
+		}
+		fmt.Fprintf(&buf, "\t\tcore.NewFactoryBean(%s)", c.name)
+		if c.options.Name != "" {
+			fmt.Fprintf(&buf, ".SetName(%q)", c.options.Name)
+		}
+		if c.options.Prototype {
+			buf.WriteString(".SetIsSingleton(false)")
+		}
+		if c.options.Lazy {
+			buf.WriteString(".SetLazy(true)")
+		}
+		buf.WriteString(",\n")
+	}
+	buf.WriteString("\t)\n}\n")
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated beans: %w", err)
+	}
+	return source, nil
+}
@@ -0,0 +1,335 @@
+package ioc
+
+import (
+	"context"
+	"github.com/kgip/go-spring/configuration"
+	"github.com/kgip/go-spring/core"
+	errors "github.com/kgip/go-spring/error"
+	"log"
+	"time"
+)
+
+// Application 应用,每个应用持有独立的ioc容器、配置和日志
+type Application struct {
+	container        *core.Container
+	shutdownHook     bool                      //收到退出信号时是否自动关闭容器
+	shutdown         <-chan error              //退出信号触发的关闭结果
+	moduleConditions []core.Condition          //正在注册的模块的注册条件,会添加到模块注册的所有bean上
+	modules          map[string]ModuleRegister //已注册的命名模块
+}
+
+type options struct {
+	logger         *log.Logger
+	configuration  configuration.Provider
+	configPath     string
+	configType     string
+	refreshConfig  bool
+	profiles       []string
+	shutdownHook   bool
+	destroyTimeout time.Duration
+	strictCycles   bool
+	strictInject   bool
+	overridePolicy core.OverridePolicy
+}
+
+// Option 应用配置项
+type Option func(o *options)
+
+// WithLogger 设置应用日志
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithConfiguration 设置配置提供者,设置后WithConfigPath等默认配置项不再生效
+func WithConfiguration(provider configuration.Provider) Option {
+	return func(o *options) {
+		o.configuration = provider
+	}
+}
+
+// WithConfigPath 设置默认配置的文件路径
+func WithConfigPath(path string) Option {
+	return func(o *options) {
+		o.configPath = path
+	}
+}
+
+// WithConfigType 设置默认配置的文件类型
+func WithConfigType(configType string) Option {
+	return func(o *options) {
+		o.configType = configType
+	}
+}
+
+// WithConfigRefresh 设置默认配置是否监听文件变化
+func WithConfigRefresh(refresh bool) Option {
+	return func(o *options) {
+		o.refreshConfig = refresh
+	}
+}
+
+// WithProfiles 设置默认配置激活的profile
+func WithProfiles(profiles ...string) Option {
+	return func(o *options) {
+		o.profiles = profiles
+	}
+}
+
+// WithShutdownHook 设置是否在收到SIGINT、SIGTERM信号时自动关闭容器
+func WithShutdownHook(enable bool) Option {
+	return func(o *options) {
+		o.shutdownHook = enable
+	}
+}
+
+// WithDestroyTimeout 设置容器关闭时单个bean的销毁超时时间
+func WithDestroyTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.destroyTimeout = timeout
+	}
+}
+
+// WithStrictCycles 设置严格模式,严格模式下拒绝所有循环依赖
+func WithStrictCycles(strict bool) Option {
+	return func(o *options) {
+		o.strictCycles = strict
+	}
+}
+
+// WithStrictInjection 设置严格注入模式,严格模式下依赖不存在时报错,不再注入默认值
+func WithStrictInjection(strict bool) Option {
+	return func(o *options) {
+		o.strictInject = strict
+	}
+}
+
+// WithOverridePolicy 设置同名bean的覆盖策略
+func WithOverridePolicy(policy core.OverridePolicy) Option {
+	return func(o *options) {
+		o.overridePolicy = policy
+	}
+}
+
+// NewApplication 创建应用,不同应用之间的容器相互隔离
+func NewApplication(opts ...Option) *Application {
+	o := &options{
+		logger:        log.Default(),
+		configPath:    defaultConfigPath,
+		configType:    defaultConfigType,
+		refreshConfig: refreshConfig,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	if o.logger == nil {
+		panic(errors.NilError)
+	}
+	configurationProvider := o.configuration
+	if configurationProvider == nil {
+		config := configuration.NewConfiguration(o.configPath, o.configType, o.refreshConfig, o.logger)
+		config.SetActiveProfiles(o.profiles...)
+		configurationProvider = config
+	}
+	app := &Application{container: core.NewContainer(configurationProvider, o.logger), shutdownHook: o.shutdownHook, modules: map[string]ModuleRegister{}}
+	app.container.SetDestroyTimeout(o.destroyTimeout)
+	app.container.SetStrictCycles(o.strictCycles)
+	app.container.SetStrictInjection(o.strictInject)
+	app.container.SetOverridePolicy(o.overridePolicy)
+	app.RegisterBeanPreProcessors()
+	app.RegisterBeanPostProcessors(&core.AssignBeanPostProcessor{}, &core.ProxyBeanPostProcessor{})
+	app.RegisterPreProcessors()
+	app.RegisterPostProcessors()
+	return app
+}
+
+// Container 获取应用的ioc容器
+func (app *Application) Container() *core.Container {
+	return app.container
+}
+
+func (app *Application) RegisterBeans(beans ...*core.Bean) {
+	for _, bean := range beans {
+		if !verifyBean(bean) {
+			panic(errors.BeanIllegalError)
+		}
+	}
+	for _, bean := range beans {
+		bean.AddCondition(app.moduleConditions...)
+		app.container.AddBean(bean)
+	}
+}
+
+func (app *Application) registerBeans(action func(o interface{}) *core.Bean, o ...interface{}) {
+	if len(o) > 0 {
+		beans := make([]*core.Bean, len(o))
+		for i := 0; i < len(o); i++ {
+			beans[i] = action(o[i])
+		}
+		app.RegisterBeans(beans...)
+	}
+}
+
+func (app *Application) RegisterSimpleBean(model ...interface{}) {
+	app.registerBeans(func(o interface{}) *core.Bean {
+		return core.NewBean(o)
+	}, model...)
+}
+
+func (app *Application) RegisterSimpleFactoryBean(factoryMethod ...interface{}) {
+	app.registerBeans(func(o interface{}) *core.Bean {
+		return core.NewFactoryBean(o)
+	}, factoryMethod...)
+}
+
+func (app *Application) RegisterBeanPreProcessors(processors ...core.BeanPreProcessor) {
+	for _, processor := range processors {
+		if processor == nil {
+			panic(errors.NilError)
+		}
+		app.container.AddBeanPreProcessor(processor)
+	}
+}
+
+func (app *Application) RegisterBeanPostProcessors(processors ...core.BeanPostProcessor) {
+	for _, processor := range processors {
+		if processor == nil {
+			panic(errors.NilError)
+		}
+		app.container.AddBeanPostProcessor(processor)
+	}
+}
+
+func (app *Application) RegisterPreProcessors(processors ...core.ContainerPreProcessor) {
+	for _, processor := range processors {
+		if processor == nil {
+			panic(errors.NilError)
+		}
+		app.container.AddContainerPreProcessor(processor)
+	}
+}
+
+func (app *Application) RegisterPostProcessors(processors ...core.ContainerPostProcessor) {
+	for _, processor := range processors {
+		if processor == nil {
+			panic(errors.NilError)
+		}
+		app.container.AddContainerPostProcessor(processor)
+	}
+}
+
+func (app *Application) SetConfiguration(provider configuration.Provider) {
+	if provider == nil {
+		panic(errors.NilError)
+	}
+	app.container.SetConfiguration(provider)
+}
+
+func (app *Application) setConfigInfo(action func(config *configuration.Configuration)) bool {
+	config := app.container.GetConfiguration()
+	if config == nil {
+		panic(errors.NilError)
+	}
+	if defaultConfig, ok := config.(*configuration.Configuration); ok {
+		action(defaultConfig)
+		return true
+	}
+	return false
+}
+
+func (app *Application) SetConfigPath(path string) bool {
+	return app.setConfigInfo(func(config *configuration.Configuration) {
+		config.SetPath(path)
+	})
+}
+
+func (app *Application) SetConfigType(configType string) bool {
+	return app.setConfigInfo(func(config *configuration.Configuration) {
+		config.SetConfigType(configType)
+	})
+}
+
+func (app *Application) SetConfigRefresh(refresh bool) bool {
+	return app.setConfigInfo(func(config *configuration.Configuration) {
+		config.SetRefresh(refresh)
+	})
+}
+
+// SetActiveProfiles 设置默认配置激活的profile
+func (app *Application) SetActiveProfiles(profiles ...string) bool {
+	return app.setConfigInfo(func(config *configuration.Configuration) {
+		config.SetActiveProfiles(profiles...)
+	})
+}
+
+// SetShutdownHook 设置是否在收到SIGINT、SIGTERM信号时自动关闭容器
+func (app *Application) SetShutdownHook(enable bool) {
+	app.shutdownHook = enable
+}
+
+// SetDestroyTimeout 设置容器关闭时单个bean的销毁超时时间
+func (app *Application) SetDestroyTimeout(timeout time.Duration) {
+	app.container.SetDestroyTimeout(timeout)
+}
+
+// SetStrictCycles 设置严格模式,严格模式下拒绝所有循环依赖
+func (app *Application) SetStrictCycles(strict bool) {
+	app.container.SetStrictCycles(strict)
+}
+
+// SetStrictInjection 设置严格注入模式,严格模式下依赖不存在时报错,不再注入默认值
+func (app *Application) SetStrictInjection(strict bool) {
+	app.container.SetStrictInjection(strict)
+}
+
+// SetOverridePolicy 设置同名bean的覆盖策略
+func (app *Application) SetOverridePolicy(policy core.OverridePolicy) {
+	app.container.SetOverridePolicy(policy)
+}
+
+// Overrides 获取所有同名bean覆盖的记录
+func (app *Application) Overrides() []core.BeanOverride {
+	return app.container.Overrides()
+}
+
+// AddListener 注册事件监听器,容器中实现了core.Listener接口的单例bean会被自动注册
+func (app *Application) AddListener(listener interface{}) {
+	app.container.AddListener(listener)
+}
+
+// Publish 发布事件
+func (app *Application) Publish(event interface{}) error {
+	return app.container.Publish(event)
+}
+
+// Start 启动应用,初始化失败时panic
+func (app *Application) Start() {
+	if err := app.Run(); err != nil {
+		panic(err)
+	}
+}
+
+// Run 启动应用,返回所有创建失败的bean组成的聚合错误
+func (app *Application) Run() error {
+	if err := app.container.InitE(); err != nil {
+		return err
+	}
+	if app.shutdownHook {
+		app.shutdown = app.container.EnableShutdownHook()
+	}
+	return nil
+}
+
+// Done 返回退出信号触发关闭后接收关闭结果的channel,未开启关闭钩子时返回nil,
+// 通常在main中等待该channel后自行决定退出码
+func (app *Application) Done() <-chan error {
+	return app.shutdown
+}
+
+// Stop 关闭容器,销毁所有已创建的单例bean
+func (app *Application) Stop(ctx context.Context) error {
+	return app.container.Shutdown(ctx)
+}
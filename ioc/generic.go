@@ -0,0 +1,39 @@
+package ioc
+
+import (
+	"github.com/kgip/go-spring/core"
+)
+
+// Get 从默认应用中按类型获取bean
+func Get[T any]() (T, error) {
+	return core.Get[T](application.container)
+}
+
+// GetByName 从默认应用中按名称获取T类型的bean
+func GetByName[T any](name string) (T, error) {
+	return core.GetByName[T](application.container, name)
+}
+
+// MustGet 从默认应用中按名称获取T类型的bean,失败时panic
+func MustGet[T any](name string) T {
+	instance, err := GetByName[T](name)
+	if err != nil {
+		panic(err)
+	}
+	return instance
+}
+
+// GetAll 从默认应用中获取所有可以赋值给T类型的bean,按优先级从高到低排序
+func GetAll[T any]() ([]T, error) {
+	return core.GetAll[T](application.container)
+}
+
+// Provide 向默认应用注册返回T类型的工厂方法,bean名称为T的类型名称
+func Provide[T any](factory func(c *core.Container) T) *core.Bean {
+	bean, err := core.NewProviderBean(factory)
+	if err != nil {
+		panic(err)
+	}
+	RegisterBeans(bean)
+	return bean
+}
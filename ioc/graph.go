@@ -0,0 +1,59 @@
+package ioc
+
+import (
+	errors "github.com/kgip/go-spring/error"
+	"io"
+)
+
+const (
+	GraphFormatDOT  = "dot"
+	GraphFormatJSON = "json"
+)
+
+// GraphDOT 将应用的bean依赖图渲染为Graphviz DOT格式
+func (app *Application) GraphDOT() string {
+	return app.container.Graph().DOT()
+}
+
+// GraphJSON 将应用的bean依赖图渲染为JSON格式
+func (app *Application) GraphJSON() ([]byte, error) {
+	return app.container.Graph().JSON()
+}
+
+// WriteGraph 将应用的bean依赖图写入w,format为dot或json
+func (app *Application) WriteGraph(w io.Writer, format string) error {
+	var data []byte
+	switch format {
+	case GraphFormatDOT:
+		data = []byte(app.GraphDOT())
+	case GraphFormatJSON:
+		var err error
+		if data, err = app.GraphJSON(); err != nil {
+			return err
+		}
+	default:
+		return errors.UnknownGraphFormatError.Detail(format)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// GraphDOT 将默认应用的bean依赖图渲染为Graphviz DOT格式
+func GraphDOT() string {
+	return application.GraphDOT()
+}
+
+// GraphJSON 将默认应用的bean依赖图渲染为JSON格式
+func GraphJSON() ([]byte, error) {
+	return application.GraphJSON()
+}
+
+// WriteGraph 将默认应用的bean依赖图写入w,format为dot或json
+func WriteGraph(w io.Writer, format string) error {
+	return application.WriteGraph(w, format)
+}
+
+// Validate 在启动之前校验应用的bean依赖图,返回无法解析的依赖和无法解决的循环依赖
+func (app *Application) Validate() error {
+	return app.container.Validate()
+}
@@ -0,0 +1,216 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/kgip/go-spring/configuration"
+	"github.com/kgip/go-spring/core"
+	errors "github.com/kgip/go-spring/error"
+	"strings"
+)
+
+// moduleEnabledKey 模块开关配置项,如modules.redis.enabled=false禁用redis模块
+const moduleEnabledKey = "modules.%s.enabled"
+
+// ModuleRegister 模块,在Register中向app注册bean,可选实现ModuleName、ModuleDependencies、ModuleDefaults、ModuleConditions
+type ModuleRegister interface {
+	Register(app *Application)
+}
+
+// ModuleName 命名模块,可以被其他模块依赖,可以通过modules.<name>.enabled=false禁用
+type ModuleName interface {
+	Name() string
+}
+
+// ModuleDependencies 声明模块依赖的其他命名模块,依赖的模块先注册,依赖的模块被禁用时该模块也被禁用
+type ModuleDependencies interface {
+	DependsOn() []string
+}
+
+// ModuleDefaults 模块提供的默认配置,配置文件中不存在的配置项使用默认值
+type ModuleDefaults interface {
+	Defaults() map[string]interface{}
+}
+
+// ModuleConditions 模块的注册条件,条件不满足时模块注册的所有bean都不会被实例化
+type ModuleConditions interface {
+	Conditions() []core.Condition
+}
+
+// ConditionalModule 带注册条件的模块
+type ConditionalModule interface {
+	ModuleRegister
+	ModuleConditions
+}
+
+type conditionalModule struct {
+	ModuleRegister
+	conditions []core.Condition
+}
+
+func (module *conditionalModule) Conditions() []core.Condition {
+	if conditional, ok := module.ModuleRegister.(ConditionalModule); ok {
+		return append(conditional.Conditions(), module.conditions...)
+	}
+	return module.conditions
+}
+
+// When 为模块添加注册条件,被包装的模块实现的其他可选接口仍然生效
+func When(register ModuleRegister, conditions ...core.Condition) ModuleRegister {
+	return &conditionalModule{ModuleRegister: register, conditions: conditions}
+}
+
+// moduleAs 获取模块实现的可选接口,When包装的模块查找被包装的模块
+func moduleAs[T any](register ModuleRegister) (T, bool) {
+	for {
+		if t, ok := register.(T); ok {
+			return t, true
+		}
+		wrapped, ok := register.(*conditionalModule)
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		register = wrapped.ModuleRegister
+	}
+}
+
+func moduleName(register ModuleRegister) string {
+	if named, ok := moduleAs[ModuleName](register); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+func dependenciesOf(register ModuleRegister) []string {
+	if dependencies, ok := moduleAs[ModuleDependencies](register); ok {
+		return dependencies.DependsOn()
+	}
+	return nil
+}
+
+// RegisterModules 按依赖顺序注册模块,依赖的模块需要在同一批或之前注册,
+// 模块的注册条件和命名模块的开关会添加到其注册的所有bean上
+func (app *Application) RegisterModules(registers ...ModuleRegister) {
+	sorted, err := app.sortModules(registers)
+	if err != nil {
+		panic(err)
+	}
+	for _, register := range sorted {
+		name := moduleName(register)
+		conditions := app.moduleConditions
+		app.moduleConditions = append([]core.Condition{}, conditions...)
+		if name != "" {
+			app.modules[name] = register
+			app.moduleConditions = append(app.moduleConditions, app.moduleEnabled(name))
+			app.container.GetLogger().Printf("register module:%s", name)
+		}
+		if defaults, ok := moduleAs[ModuleDefaults](register); ok {
+			app.setDefaults(defaults.Defaults())
+		}
+		if conditional, ok := moduleAs[ModuleConditions](register); ok {
+			app.moduleConditions = append(app.moduleConditions, conditional.Conditions()...)
+		}
+		register.Register(app)
+		app.moduleConditions = conditions
+	}
+}
+
+// sortModules 对模块进行拓扑排序,依赖的模块排在前面,其余模块保持注册顺序
+func (app *Application) sortModules(registers []ModuleRegister) ([]ModuleRegister, error) {
+	var modules []ModuleRegister
+	batch := map[string]int{} //命名模块在modules中的下标
+	for _, register := range registers {
+		if register == nil {
+			continue
+		}
+		if name := moduleName(register); name != "" {
+			if _, ok := batch[name]; ok || app.modules[name] != nil {
+				return nil, errors.DuplicateModuleError.Detail(name)
+			}
+			batch[name] = len(modules)
+		}
+		modules = append(modules, register)
+	}
+	const (
+		visiting = iota + 1
+		visited
+	)
+	states := make([]int, len(modules))
+	var sorted []ModuleRegister
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		name := moduleName(modules[i])
+		path = append(path, name)
+		states[i] = visiting
+		for _, dependency := range dependenciesOf(modules[i]) {
+			if app.modules[dependency] != nil {
+				continue
+			}
+			index, ok := batch[dependency]
+			if !ok {
+				return errors.UnknownModuleError.Detail(fmt.Sprintf("module %s depends on unknown module %s", name, dependency))
+			}
+			switch states[index] {
+			case visiting:
+				return errors.CircularDependencyError.Detail("modules: " + strings.Join(append(path, dependency), " -> "))
+			case 0:
+				if err := visit(index, path); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+		sorted = append(sorted, modules[i])
+		return nil
+	}
+	for i := range modules {
+		if states[i] == 0 {
+			if err := visit(i, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sorted, nil
+}
+
+func (app *Application) setDefaults(defaults map[string]interface{}) {
+	if len(defaults) == 0 {
+		return
+	}
+	provider, ok := app.container.GetConfiguration().(configuration.DefaultsProvider)
+	if !ok {
+		app.container.GetLogger().Println("configuration provider doesn't support default values, module defaults ignored")
+		return
+	}
+	for key, value := range defaults {
+		provider.SetDefault(key, value)
+	}
+}
+
+// moduleEnabled 模块及其依赖的模块都没有被禁用时满足条件
+func (app *Application) moduleEnabled(name string) core.Condition {
+	return core.ConditionFunc(func(c *core.Container) bool {
+		return app.isModuleEnabled(c, name, map[string]bool{})
+	})
+}
+
+func (app *Application) isModuleEnabled(c *core.Container, name string, checked map[string]bool) bool {
+	if checked[name] {
+		return true
+	}
+	checked[name] = true
+	if enabled := c.GetConfiguration().GetConfig(fmt.Sprintf(moduleEnabledKey, name)); enabled != nil && fmt.Sprint(enabled) == "false" {
+		return false
+	}
+	for _, dependency := range dependenciesOf(app.modules[name]) {
+		if !app.isModuleEnabled(c, dependency, checked) {
+			return false
+		}
+	}
+	return true
+}
+
+// RegisterModules 向默认应用按依赖顺序注册模块
+func RegisterModules(registers ...ModuleRegister) {
+	application.RegisterModules(registers...)
+}
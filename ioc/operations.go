@@ -1,23 +1,12 @@
 package ioc
 
 import (
+	"context"
 	"github.com/kgip/go-spring/configuration"
 	"github.com/kgip/go-spring/core"
-	errors "github.com/kgip/go-spring/error"
+	"time"
 )
 
-type ModuleRegister interface {
-	Register()
-}
-
-func RegisterModules(registers ...ModuleRegister) {
-	for _, register := range registers {
-		if register != nil {
-			register.Register()
-		}
-	}
-}
-
 func verifyBean(bean *core.Bean) bool {
 	//bean名称不能为空
 	if bean.GetName() == "" {
@@ -31,111 +20,114 @@ func verifyBean(bean *core.Bean) bool {
 }
 
 func RegisterBeans(beans ...*core.Bean) {
-	for _, bean := range beans {
-		if !verifyBean(bean) {
-			panic(errors.BeanIllegalError)
-		}
-	}
-	for _, bean := range beans {
-		container.AddBean(bean)
-	}
-}
-
-func registerBeans(action func(o interface{}) *core.Bean, o ...interface{}) {
-	if len(o) > 0 {
-		beans := make([]*core.Bean, len(o))
-		for i := 0; i < len(o); i++ {
-			beans[i] = action(o)
-		}
-		RegisterBeans(beans...)
-	}
+	application.RegisterBeans(beans...)
 }
 
 func RegisterSimpleBean(model ...interface{}) {
-	registerBeans(func(o interface{}) *core.Bean {
-		return core.NewBean(model)
-	}, model...)
+	application.RegisterSimpleBean(model...)
 }
 
 func RegisterSimpleFactoryBean(factoryMethod ...interface{}) {
-	registerBeans(func(o interface{}) *core.Bean {
-		return core.NewFactoryBean(factoryMethod)
-	}, factoryMethod...)
+	application.RegisterSimpleFactoryBean(factoryMethod...)
 }
 
 func RegisterBeanPreProcessors(processors ...core.BeanPreProcessor) {
-	for _, processor := range processors {
-		if processor == nil {
-			panic(errors.NilError)
-		}
-		container.AddBeanPreProcessor(processor)
-	}
+	application.RegisterBeanPreProcessors(processors...)
 }
 
 func RegisterBeanPostProcessors(processors ...core.BeanPostProcessor) {
-	for _, processor := range processors {
-		if processor == nil {
-			panic(errors.NilError)
-		}
-		container.AddBeanPostProcessor(processor)
-	}
+	application.RegisterBeanPostProcessors(processors...)
 }
 
 func RegisterPreProcessors(processors ...core.ContainerPreProcessor) {
-	for _, processor := range processors {
-		if processor == nil {
-			panic(errors.NilError)
-		}
-		container.AddContainerPreProcessor(processor)
-	}
+	application.RegisterPreProcessors(processors...)
 }
 
 func RegisterPostProcessors(processors ...core.ContainerPostProcessor) {
-	for _, processor := range processors {
-		if processor == nil {
-			panic(errors.NilError)
-		}
-		container.AddContainerPostProcessor(processor)
-	}
+	application.RegisterPostProcessors(processors...)
 }
 
 func SetConfiguration(provider configuration.Provider) {
-	if provider == nil {
-		panic(errors.NilError)
-	}
-	container.SetConfiguration(provider)
-}
-
-func setConfigInfo(action func(config *configuration.Configuration)) bool {
-	config := container.GetConfiguration()
-	if config == nil {
-		panic(errors.NilError)
-	}
-	if defaultConfig, ok := config.(*configuration.Configuration); ok {
-		action(defaultConfig)
-		return true
-	}
-	return false
+	application.SetConfiguration(provider)
 }
 
 func SetConfigPath(path string) bool {
-	return setConfigInfo(func(config *configuration.Configuration) {
-		config.SetPath(path)
-	})
+	return application.SetConfigPath(path)
 }
 
 func SetConfigType(configType string) bool {
-	return setConfigInfo(func(config *configuration.Configuration) {
-		config.SetConfigType(configType)
-	})
+	return application.SetConfigType(configType)
 }
 
 func SetConfigRefresh(refresh bool) bool {
-	return setConfigInfo(func(config *configuration.Configuration) {
-		config.SetRefresh(refresh)
-	})
+	return application.SetConfigRefresh(refresh)
+}
+
+// SetActiveProfiles 设置默认配置激活的profile
+func SetActiveProfiles(profiles ...string) bool {
+	return application.SetActiveProfiles(profiles...)
+}
+
+// SetShutdownHook 设置是否在收到SIGINT、SIGTERM信号时自动关闭容器
+func SetShutdownHook(enable bool) {
+	application.SetShutdownHook(enable)
+}
+
+// Done 返回退出信号触发关闭后接收关闭结果的channel,未开启关闭钩子时返回nil
+func Done() <-chan error {
+	return application.Done()
+}
+
+// SetDestroyTimeout 设置容器关闭时单个bean的销毁超时时间
+func SetDestroyTimeout(timeout time.Duration) {
+	application.SetDestroyTimeout(timeout)
+}
+
+// SetStrictCycles 设置严格模式,严格模式下拒绝所有循环依赖
+func SetStrictCycles(strict bool) {
+	application.SetStrictCycles(strict)
+}
+
+// SetStrictInjection 设置严格注入模式,严格模式下依赖不存在时报错,不再注入默认值
+func SetStrictInjection(strict bool) {
+	application.SetStrictInjection(strict)
+}
+
+// SetOverridePolicy 设置同名bean的覆盖策略
+func SetOverridePolicy(policy core.OverridePolicy) {
+	application.SetOverridePolicy(policy)
+}
+
+// Overrides 获取所有同名bean覆盖的记录
+func Overrides() []core.BeanOverride {
+	return application.Overrides()
+}
+
+// AddListener 注册事件监听器
+func AddListener(listener interface{}) {
+	application.AddListener(listener)
+}
+
+// Publish 发布事件
+func Publish(event interface{}) error {
+	return application.Publish(event)
+}
+
+// GetApplication 获取默认应用
+func GetApplication() *Application {
+	return application
 }
 
 func Start() {
-	container.Init()
+	application.Start()
+}
+
+// Run 启动默认应用,返回所有创建失败的bean组成的聚合错误
+func Run() error {
+	return application.Run()
+}
+
+// Stop 关闭容器,销毁所有已创建的单例bean
+func Stop(ctx context.Context) error {
+	return application.Stop(ctx)
 }
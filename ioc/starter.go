@@ -1,11 +1,5 @@
 package ioc
 
-import (
-	"github.com/kgip/go-spring/configuration"
-	"github.com/kgip/go-spring/core"
-	"log"
-)
-
 const (
 	defaultConfigPath = "./config.yaml"
 	defaultConfigType = "yaml"
@@ -13,15 +7,9 @@ const (
 )
 
 var (
-	container *core.Container
+	application *Application //默认应用,包级函数均作用于该应用
 )
 
 func init() {
-	logger := log.Default()
-	configurationProvider := configuration.NewConfiguration(defaultConfigPath, defaultConfigType, refreshConfig, logger)
-	container = core.NewContainer(configurationProvider, logger)
-	RegisterBeanPreProcessors()
-	RegisterBeanPostProcessors(&core.AssignBeanPostProcessor{})
-	RegisterPreProcessors()
-	RegisterPostProcessors()
+	application = NewApplication()
 }
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"github.com/kgip/go-spring/ioc"
+	"io"
+	"log"
+	"testing"
+)
+
+type mapConfiguration struct {
+	configs map[string]interface{}
+	loaded  int
+}
+
+func (c *mapConfiguration) Load() {
+	c.loaded++
+}
+
+func (c *mapConfiguration) SetDefault(configKey string, value interface{}) {
+	if _, ok := c.configs[configKey]; !ok {
+		c.configs[configKey] = value
+	}
+}
+
+func (c *mapConfiguration) GetConfig(configKey string) interface{} {
+	return c.configs[configKey]
+}
+
+func newTestApplication(opts ...ioc.Option) (*ioc.Application, *mapConfiguration) {
+	config := &mapConfiguration{configs: map[string]interface{}{}}
+	opts = append([]ioc.Option{ioc.WithConfiguration(config), ioc.WithLogger(log.New(io.Discard, "", 0))}, opts...)
+	return ioc.NewApplication(opts...), config
+}
+
+func TestIsolatedApplications(t *testing.T) {
+	app1, config1 := newTestApplication()
+	app2, config2 := newTestApplication()
+	app1.Start()
+	app1.Start()
+	app2.Start()
+	if config1.loaded != 1 || config2.loaded != 1 {
+		t.Fatalf("each application should load its own configuration once, got %d and %d", config1.loaded, config2.loaded)
+	}
+	if app1.Container() == app2.Container() {
+		t.Fatal("applications should not share a container")
+	}
+}
@@ -0,0 +1,39 @@
+package component
+
+import (
+	"github.com/kgip/go-spring/core"
+)
+
+//go:generate sh -c "cd ../../cmd/go-spring && go run . scan ../../test/component"
+
+// Repository 以标签中的名称注册
+type Repository struct {
+	core.Component `bean:"repository"`
+}
+
+// Session 原型bean,每次注入都创建新的实例
+type Session struct {
+	core.Component `bean:",scope=prototype"`
+	Repository     *Repository
+}
+
+// Cache 懒加载bean,Inits记录创建次数
+type Cache struct {
+	core.Component `bean:"cache,lazy"`
+}
+
+var Inits int
+
+func (c *Cache) Init(container *core.Container) {
+	Inits++
+}
+
+type Clock struct {
+	Zone string
+}
+
+// NewClock 时钟
+// go-spring:bean clock,lazy
+func NewClock() *Clock {
+	return &Clock{Zone: "UTC"}
+}
@@ -0,0 +1,22 @@
+// Code generated by go-spring scan. DO NOT EDIT.
+
+package component
+
+import (
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+)
+
+func init() {
+	RegisterBeans(ioc.GetApplication())
+}
+
+// RegisterBeans 向app注册包中扫描到的组件
+func RegisterBeans(app *ioc.Application) {
+	app.RegisterBeans(
+		core.NewBean(&Repository{}),
+		core.NewBean(&Session{}),
+		core.NewBean(&Cache{}),
+		core.NewFactoryBean(NewClock).SetName("clock").SetLazy(true),
+	)
+}
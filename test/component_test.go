@@ -0,0 +1,68 @@
+package test
+
+import (
+	"bytes"
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/gen"
+	"github.com/kgip/go-spring/test/component"
+	"os"
+	"testing"
+)
+
+type SessionHolder struct {
+	First  *component.Session
+	Second *component.Session
+}
+
+func TestComponentTag(t *testing.T) {
+	app, _ := newTestApplication()
+	component.Inits = 0
+	component.RegisterBeans(app)
+	app.RegisterBeans(core.NewBean(&SessionHolder{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	holder := app.Container().GetBeanInstanceByName("SessionHolder").(*SessionHolder)
+	if holder.First == holder.Second {
+		t.Fatal("prototype component should be created for every injection")
+	}
+	if holder.First.Repository != app.Container().GetBeanInstanceByName("repository") {
+		t.Fatal("component should be registered with the name in its bean tag")
+	}
+	if component.Inits != 0 {
+		t.Fatal("lazy component should not be created on startup")
+	}
+	cache := app.Container().GetBeanInstanceByName("cache")
+	if component.Inits != 1 || cache != app.Container().GetBeanInstanceByName("cache") {
+		t.Fatalf("lazy component should be created once on first lookup, got %d", component.Inits)
+	}
+	if clock := app.Container().GetBeanInstanceByName("clock").(*component.Clock); clock.Zone != "UTC" {
+		t.Fatal("lazy factory bean should be created on first lookup")
+	}
+}
+
+func TestComponentTagError(t *testing.T) {
+	type Invalid struct {
+		core.Component `bean:"invalid,scope=request"`
+	}
+	if _, err := core.NewBeanE(&Invalid{}); err == nil {
+		t.Fatal("unknown scope in bean tag should be rejected")
+	}
+	if _, err := core.ParseComponentTag("name,eager"); err == nil {
+		t.Fatal("unknown option in bean tag should be rejected")
+	}
+}
+
+func TestGeneratedBeansUpToDate(t *testing.T) {
+	source, err := gen.Components("component")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := os.ReadFile("component/" + gen.BeansFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, generated) {
+		t.Fatal("generated beans are out of date, run go generate ./test/component")
+	}
+}
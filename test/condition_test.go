@@ -0,0 +1,76 @@
+package test
+
+import (
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+	"testing"
+)
+
+type RedisClient struct{}
+
+type MemoryCache struct{}
+
+type cacheModule struct{}
+
+func (m *cacheModule) Register(app *ioc.Application) {
+	app.RegisterBeans(core.NewBean(&RedisClient{}))
+}
+
+func TestConditionalBeans(t *testing.T) {
+	app, config := newTestApplication()
+	config.configs["redis.enabled"] = true
+	t.Setenv("GO_SPRING_TEST_ENV", "dev")
+	app.RegisterModules(ioc.When(&cacheModule{}, core.OnProperty("redis.enabled", "true")))
+	app.RegisterBeans(
+		core.NewBean(&MemoryCache{}).AddCondition(core.OnMissingBean((*RedisClient)(nil))),
+		core.NewBean(&DataSource{}).AddCondition(core.OnBean("RedisClient"), core.OnEnv("GO_SPRING_TEST_ENV", "dev")),
+		core.NewBean(&Counter{}).AddCondition(core.OnEnv("GO_SPRING_TEST_ENV", "prod")),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	if c.GetBeanInstanceByName("RedisClient") == nil || c.GetBeanInstanceByName("DataSource") == nil {
+		t.Fatal("beans with matched conditions should be registered")
+	}
+	if c.GetBeanInstanceByName("MemoryCache") != nil || c.GetBeanInstanceByName("Counter") != nil {
+		t.Fatal("beans with unmatched conditions should be skipped")
+	}
+}
+
+func TestConditionalModuleDisabled(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterModules(ioc.When(&cacheModule{}, core.OnProperty("redis.enabled", "true")))
+	app.RegisterBeans(core.NewBean(&MemoryCache{}).AddCondition(core.OnMissingBean(&RedisClient{})))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if app.Container().GetBeanInstanceByName("RedisClient") != nil || app.Container().GetBeanInstanceByName("MemoryCache") == nil {
+		t.Fatal("module beans should be skipped and fallback registered")
+	}
+}
+
+type LocalCache struct{}
+
+type RemoteCache struct{}
+
+type CacheMetrics struct{}
+
+func TestConditionOrdering(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&CacheMetrics{}).AddCondition(core.OnBean("LocalCache")),
+		core.NewBean(&LocalCache{}).AddCondition(core.OnMissingBean("RemoteCache")),
+		core.NewBean(&RemoteCache{}).AddCondition(core.OnProperty("cache.remote", "true")),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	if c.GetBeanInstanceByName("RemoteCache") != nil {
+		t.Fatal("bean with unmatched property condition should be skipped")
+	}
+	if c.GetBeanInstanceByName("LocalCache") == nil || c.GetBeanInstanceByName("CacheMetrics") == nil {
+		t.Fatal("bean conditions should see the beans left after property conditions regardless of registration order")
+	}
+}
@@ -0,0 +1,357 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/kgip/go-spring/core"
+	iocErrors "github.com/kgip/go-spring/error"
+	"github.com/kgip/go-spring/ioc"
+	"github.com/kgip/go-spring/test/a"
+	"github.com/kgip/go-spring/test/b"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var destroyed []string
+
+type Repository struct{}
+
+func (r *Repository) Close() error {
+	destroyed = append(destroyed, "Repository")
+	return nil
+}
+
+type Service struct {
+	Repository *Repository
+}
+
+func (s *Service) Destroy(ctx context.Context) error {
+	destroyed = append(destroyed, "Service")
+	return errors.New("service destroy failed")
+}
+
+func TestFieldInjectionAndShutdown(t *testing.T) {
+	app, _ := newTestApplication()
+	destroyed = nil
+	app.RegisterBeans(core.NewBean(&Service{}), core.NewBean(&Repository{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	service := app.Container().GetBeanInstanceByName("Service").(*Service)
+	if service.Repository != app.Container().GetBeanInstanceByName("Repository") {
+		t.Fatal("Repository should be injected into Service")
+	}
+	err := app.Stop(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "service destroy failed") {
+		t.Fatalf("destroy error should be reported, got %v", err)
+	}
+	if strings.Join(destroyed, ",") != "Service,Repository" {
+		t.Fatalf("beans should be destroyed in reverse dependency order, got %v", destroyed)
+	}
+}
+
+type SlowResource struct{}
+
+func (*SlowResource) Destroy(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+type FailingResource struct{}
+
+func (*FailingResource) Close() error {
+	return errors.New("resource close failed")
+}
+
+func TestShutdownTimeoutAndErrors(t *testing.T) {
+	app, _ := newTestApplication(ioc.WithDestroyTimeout(10 * time.Millisecond))
+	destroyed = nil
+	app.RegisterBeans(
+		core.NewBean(&Repository{}),
+		core.NewBean(&SlowResource{}),
+		core.NewBean(&Service{}),
+		core.NewBean(&FailingResource{}),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	err := app.Stop(context.Background())
+	multi, ok := err.(iocErrors.MultiError)
+	if !ok || len(multi) != 3 {
+		t.Fatalf("every destroy failure should be aggregated, got %v", err)
+	}
+	for _, message := range []string{"resource close failed", "service destroy failed", "Bean destroy timeout"} {
+		if !strings.Contains(err.Error(), message) {
+			t.Fatalf("shutdown error should contain %q, got %v", message, err)
+		}
+	}
+	if destroyed[len(destroyed)-1] != "Repository" {
+		t.Fatalf("a timed out bean should not stop the remaining beans from being destroyed, got %v", destroyed)
+	}
+}
+
+func TestShutdownHook(t *testing.T) {
+	app, _ := newTestApplication()
+	destroyed = nil
+	app.RegisterBeans(core.NewBean(&Repository{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	done := app.Container().EnableShutdownHook(syscall.SIGUSR1)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil || strings.Join(destroyed, ",") != "Repository" {
+			t.Fatalf("signal should shutdown the container, got %v %v", err, destroyed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown hook should report the shutdown result")
+	}
+}
+
+type Broken struct{}
+
+func (*Broken) Init(c *core.Container) {
+	panic("broken init")
+}
+
+type DependsOnBroken struct {
+	Broken *Broken `name:"Broken"`
+}
+
+func TestInitAggregatesErrors(t *testing.T) {
+	app, _ := newTestApplication()
+	destroyed = nil
+	app.RegisterBeans(core.NewBean(&Repository{}), core.NewBean(&Broken{}), core.NewBean(&DependsOnBroken{}))
+	err := app.Run()
+	if strings.Join(destroyed, ",") != "Repository" {
+		t.Fatalf("beans created before the failure should be destroyed, got %v", destroyed)
+	}
+	multiErr, ok := err.(iocErrors.MultiError)
+	if !ok || len(multiErr) != 2 {
+		t.Fatalf("expected every failed bean to be reported, got %v", err)
+	}
+	var creationErr *iocErrors.BeanCreationError
+	if !errors.As(multiErr[1], &creationErr) || strings.Join(creationErr.Path, " -> ") != "DependsOnBroken -> Broken" {
+		t.Fatalf("unexpected creation error %v", multiErr[1])
+	}
+}
+
+type CycleA struct {
+	B *CycleB
+}
+
+type CycleB struct {
+	C *CycleC `name:"CycleC"`
+}
+
+type CycleC struct {
+	A *CycleA
+}
+
+func TestCircularFieldInjection(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&CycleA{}), core.NewBean(&CycleB{}), core.NewBean(&CycleC{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	a := app.Container().GetBeanInstanceByName("CycleA").(*CycleA)
+	if a.B == nil || a.B.C == nil || a.B.C.A != a {
+		t.Fatal("singletons in a field injection cycle should reference each other")
+	}
+}
+
+func TestCircularDependencyChain(t *testing.T) {
+	app, _ := newTestApplication(ioc.WithStrictCycles(true))
+	app.RegisterBeans(core.NewBean(&CycleA{}), core.NewBean(&CycleB{}), core.NewBean(&CycleC{}))
+	err := app.Run()
+	if err == nil || !strings.Contains(err.Error(), "CycleA(field B) -> CycleB(field C) -> CycleC(field A) -> CycleA") {
+		t.Fatalf("expected full cycle chain, got %v", err)
+	}
+}
+
+type Config struct {
+	Dsn string
+}
+
+type Db struct {
+	Dsn    string
+	Checks []HealthCheck
+	closed bool
+}
+
+type Repo struct {
+	Db *Db
+}
+
+func TestConstructorInjection(t *testing.T) {
+	app, _ := newTestApplication()
+	var db *Db
+	app.RegisterBeans(
+		core.NewBean(Config{Dsn: "memory"}).SetName("config"),
+		core.NewFactoryBean(func(cfg Config, checks ...HealthCheck) (*Db, func(), error) {
+			db = &Db{Dsn: cfg.Dsn, Checks: checks}
+			return db, func() { db.closed = true }, nil
+		}),
+		core.NewFactoryBean(func(db *Db, c *core.Container) (*Repo, error) {
+			return &Repo{Db: db}, nil
+		}),
+		core.NewBean(&DbHealthCheck{}),
+		core.NewBean(&CacheHealthCheck{}),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	repo := app.Container().GetBeanInstanceByName("Repo").(*Repo)
+	if repo.Db != db || len(db.Checks) != 2 {
+		t.Fatalf("factory parameters should be injected, got %+v", db)
+	}
+	if err := app.Stop(context.Background()); err != nil || !db.closed {
+		t.Fatalf("cleanup func should run on shutdown, err %v", err)
+	}
+}
+
+func TestConstructorError(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewFactoryBean(func() (*Db, error) {
+		return nil, errors.New("connection refused")
+	}))
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("factory error should fail startup, got %v", err)
+	}
+}
+
+func TestConstructorErrorCleanup(t *testing.T) {
+	app, _ := newTestApplication()
+	cleaned := false
+	app.RegisterBeans(core.NewFactoryBean(func() (*Db, func(), error) {
+		return &Db{}, func() { cleaned = true }, errors.New("migration failed")
+	}))
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "migration failed") {
+		t.Fatalf("factory error should fail startup, got %v", err)
+	}
+	if !cleaned {
+		t.Fatal("cleanup func returned with an error should run immediately")
+	}
+}
+
+func TestPrototypeCleanupRejected(t *testing.T) {
+	app, _ := newTestApplication()
+	bean := core.NewFactoryBean(func() (*Db, func()) {
+		return &Db{}, func() {}
+	}).SetIsSingleton(false)
+	if added, err := app.Container().AddBeanE(bean); added || !errors.Is(err, iocErrors.BeanIllegalError) {
+		t.Fatalf("prototype bean with cleanup func should be rejected, got %v", err)
+	}
+}
+
+type FactoryCycle struct {
+	Loop *FactoryLoop
+}
+
+type FactoryLoop struct {
+	Cycle *FactoryCycle
+}
+
+func TestCircularFactoryDependency(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewFactoryBean(func(loop *FactoryLoop) *FactoryCycle {
+			return &FactoryCycle{Loop: loop}
+		}),
+		core.NewBean(&FactoryLoop{}),
+	)
+	err := app.Run()
+	if err == nil || !strings.Contains(err.Error(), "FactoryCycle(param 0) -> FactoryLoop(field Cycle) -> FactoryCycle") {
+		t.Fatalf("cycles through factory parameters can't be resolved, got %v", err)
+	}
+}
+
+func TestBeanOverridePolicy(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&DataSource{}).SetName("db").SetPriority(1))
+	app.RegisterBeans(core.NewBean(&DataSource{}).SetName("db"))
+	app.RegisterBeans(core.NewBean(&DataSource{}).SetName("db").SetPriority(2))
+	overrides := app.Overrides()
+	if len(overrides) != 2 || overrides[0].Kept == overrides[0].Replaced {
+		t.Fatalf("expected two overrides with distinct sites, got %v", overrides)
+	}
+	if !strings.Contains(overrides[1].Kept, "container_test.go:") || overrides[1].Kept == overrides[0].Kept {
+		t.Fatalf("override should report the registration site of the winning bean, got %v", overrides)
+	}
+
+	app, _ = newTestApplication(ioc.WithOverridePolicy(core.OverrideLastWins))
+	app.RegisterBeans(core.NewBean(&DataSource{}).SetName("db").SetPriority(1))
+	if added, _ := app.Container().AddBeanE(core.NewBean(&DataSource{}).SetName("db")); !added || len(app.Overrides()) != 1 {
+		t.Fatal("last registered bean should win regardless of priority")
+	}
+
+	app, _ = newTestApplication(ioc.WithOverridePolicy(core.OverrideForbidden))
+	app.RegisterBeans(core.NewBean(&DataSource{}).SetName("db"))
+	if _, err := app.Container().AddBeanE(core.NewBean(&DataSource{}).SetName("db")); !errors.Is(err, iocErrors.BeanOverrideError) || !strings.Contains(err.Error(), "container_test.go:") {
+		t.Fatalf("expected forbidden override error with registration sites, got %v", err)
+	}
+}
+
+func TestLookupNamedBeanByType(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&DataSource{Url: "db"}).SetName("db"))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	db := c.GetBeanInstanceByName("db")
+	if instance, err := c.GetBeanInstanceByStruct(&DataSource{}); err != nil || instance != db {
+		t.Fatalf("explicitly named bean should be found by type, got %v %v", instance, err)
+	}
+	if c.GetInstance(reflect.TypeOf(&DataSource{})) != db {
+		t.Fatal("GetInstance should return the explicitly named bean instead of a new value")
+	}
+
+	app, _ = newTestApplication()
+	app.RegisterBeans(core.NewBean(&DataSource{}).SetName("primary"), core.NewBean(&DataSource{}).SetName("replica"))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Container().GetBeanInstanceByStruct(&DataSource{}); !errors.Is(err, iocErrors.AmbiguousBeanError) {
+		t.Fatalf("expected ambiguous bean error, got %v", err)
+	}
+}
+
+type InitConsumer struct {
+	A *a.Init
+	B *b.Init
+}
+
+func TestPackageQualifiedBeanNames(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&InitConsumer{}), core.NewBean(&a.Init{}), core.NewBean(&b.Init{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(app.Overrides()) != 0 {
+		t.Fatalf("beans of different packages should not override each other, got %v", app.Overrides())
+	}
+	c := app.Container()
+	initA, _ := c.GetBeanInstanceByStruct(&a.Init{})
+	initB, _ := c.GetBeanInstanceByStruct(b.Init{})
+	if initA == nil || initB == nil || initA == initB {
+		t.Fatal("struct lookup should be keyed on the full type")
+	}
+	if c.GetBeanInstanceByName("github.com/kgip/go-spring/test/a.Init") != initA {
+		t.Fatal("colliding beans should be registered with package qualified names")
+	}
+	consumer := c.GetBeanInstanceByName("InitConsumer").(*InitConsumer)
+	if consumer.A != initA || consumer.B != initB {
+		t.Fatal("fields should be injected by full type")
+	}
+	if _, err := c.GetBeanInstanceByNameE("Init"); !errors.Is(err, iocErrors.AmbiguousBeanError) {
+		t.Fatalf("expected ambiguous short name error, got %v", err)
+	}
+}
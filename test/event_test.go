@@ -0,0 +1,204 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/kgip/go-spring/core"
+	iocErrors "github.com/kgip/go-spring/error"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type OrderPlaced struct {
+	ID int
+}
+
+type OrderService struct {
+	Publisher core.EventPublisher
+}
+
+func (s *OrderService) Place(id int) error {
+	return s.Publisher.Publish(OrderPlaced{ID: id})
+}
+
+type eventRecorder struct {
+	lock   sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) record(event string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, event)
+}
+
+var recorder = &eventRecorder{}
+
+type AuditListener struct{}
+
+func (*AuditListener) GetPriority() int {
+	return 1
+}
+
+func (*AuditListener) OnEvent(event OrderPlaced) error {
+	recorder.record("audit")
+	return nil
+}
+
+type MailListener struct{}
+
+func (*MailListener) GetPriority() int {
+	return 2
+}
+
+func (*MailListener) OnEvent(event OrderPlaced) error {
+	recorder.record("mail")
+	return nil
+}
+
+type LifecycleListener struct {
+	Done chan struct{}
+}
+
+func (*LifecycleListener) Async() bool {
+	return true
+}
+
+func (l *LifecycleListener) OnEvent(event core.ContainerStartedEvent) error {
+	close(l.Done)
+	return nil
+}
+
+func TestEventListeners(t *testing.T) {
+	recorder = &eventRecorder{}
+	app, _ := newTestApplication()
+	var created []string
+	app.AddListener(createdListener(func(event core.BeanCreatedEvent) {
+		created = append(created, event.Name)
+	}))
+	lifecycle := &LifecycleListener{Done: make(chan struct{})}
+	app.RegisterBeans(
+		core.NewBean(&OrderService{}),
+		core.NewBean(&AuditListener{}),
+		core.NewBean(&MailListener{}),
+		core.NewFactoryBean(func() *LifecycleListener { return lifecycle }),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-lifecycle.Done
+	if len(created) != 4 {
+		t.Fatalf("expected created events of all beans, got %v", created)
+	}
+	service := app.Container().GetBeanInstanceByName("OrderService").(*OrderService)
+	if err := service.Place(1); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(recorder.events, ",") != "mail,audit" {
+		t.Fatalf("listeners should be called by priority, got %v", recorder.events)
+	}
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type createdListener func(event core.BeanCreatedEvent)
+
+func (f createdListener) OnEvent(event core.BeanCreatedEvent) error {
+	f(event)
+	return nil
+}
+
+type CreationAudit struct {
+	Created []string
+}
+
+func (a *CreationAudit) OnEvent(event core.BeanCreatedEvent) error {
+	a.Created = append(a.Created, event.Name)
+	return nil
+}
+
+func TestBeanListenerReceivesCreatedEvents(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&OrderService{}),
+		core.NewBean(&Repository{}),
+		core.NewBean(&CreationAudit{}),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	audit := app.Container().GetBeanInstanceByName("CreationAudit").(*CreationAudit)
+	if strings.Join(audit.Created, ",") != "CreationAudit,OrderService,Repository" {
+		t.Fatalf("listener bean should be created first and receive created events of other beans, got %v", audit.Created)
+	}
+}
+
+type failingListener struct{}
+
+func (failingListener) OnEvent(event OrderPlaced) error {
+	return errors.New("mail server down")
+}
+
+func TestEventListenerError(t *testing.T) {
+	app, _ := newTestApplication()
+	app.AddListener(failingListener{})
+	if err := app.Publish(OrderPlaced{ID: 1}); err == nil || !strings.Contains(err.Error(), "mail server down") {
+		t.Fatalf("expected listener error, got %v", err)
+	}
+	if err := app.Container().AddListenerE(&OrderService{}); err == nil {
+		t.Fatal("beans without OnEvent should not be accepted as listeners")
+	}
+}
+
+type asyncOrderListener struct{}
+
+func (asyncOrderListener) Async() bool {
+	return true
+}
+
+func (l asyncOrderListener) OnEvent(event OrderPlaced) error {
+	return nil
+}
+
+func TestPublishDuringShutdown(t *testing.T) {
+	app, _ := newTestApplication()
+	app.AddListener(asyncOrderListener{})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := app.Publish(OrderPlaced{ID: id}); err != nil && !errors.Is(err, iocErrors.EventsClosedError) {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if err := app.Publish(OrderPlaced{ID: 1}); !errors.Is(err, iocErrors.EventsClosedError) {
+		t.Fatalf("events should not be published after shutdown, got %v", err)
+	}
+}
+
+type failingStartedListener struct{}
+
+func (failingStartedListener) OnEvent(event core.ContainerStartedEvent) error {
+	return errors.New("notify failed")
+}
+
+func TestStartedListenerError(t *testing.T) {
+	app, _ := newTestApplication()
+	app.AddListener(failingStartedListener{})
+	if err := app.Run(); err != nil {
+		t.Fatalf("listener error on started event should not fail startup, got %v", err)
+	}
+}
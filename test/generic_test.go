@@ -0,0 +1,44 @@
+package test
+
+import (
+	"errors"
+	"github.com/kgip/go-spring/core"
+	iocErrors "github.com/kgip/go-spring/error"
+	"testing"
+)
+
+type Clock struct {
+	Zone string
+}
+
+func TestGenericLookup(t *testing.T) {
+	app, _ := newTestApplication()
+	clockBean, err := core.NewProviderBean(func(c *core.Container) *Clock {
+		return &Clock{Zone: "UTC"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.RegisterBeans(clockBean, core.NewBean(&DbHealthCheck{}), core.NewBean(&CacheHealthCheck{}).SetPriority(10))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	clock, err := core.Get[*Clock](c)
+	if err != nil || clock.Zone != "UTC" {
+		t.Fatalf("expected provided clock, got %v %v", clock, err)
+	}
+	if named, err := core.GetByName[*Clock](c, "Clock"); err != nil || named != clock {
+		t.Fatalf("expected same clock by name, got %v %v", named, err)
+	}
+	checks, err := core.GetAll[HealthCheck](c)
+	if err != nil || len(checks) != 2 {
+		t.Fatalf("expected all health checks, got %v %v", checks, err)
+	}
+	if _, err := core.Get[*DataSource](c); !errors.Is(err, iocErrors.NoSuchBeanError) {
+		t.Fatalf("expected NoSuchBeanError, got %v", err)
+	}
+	if _, err := core.GetByName[*DataSource](c, "Clock"); !errors.Is(err, iocErrors.TypeNotMatchError) {
+		t.Fatalf("expected TypeNotMatchError, got %v", err)
+	}
+}
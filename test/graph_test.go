@@ -0,0 +1,58 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/kgip/go-spring/core"
+	"strings"
+	"testing"
+)
+
+func TestGraphExport(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&Service{}), core.NewBean(&Repository{}).SetIsSingleton(false))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	dot := app.GraphDOT()
+	if !strings.Contains(dot, `"Service" -> "Repository" [label="field Repository"];`) {
+		t.Fatalf("missing edge in dot output:\n%s", dot)
+	}
+	data, err := app.GraphJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var graph core.BeanGraph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Nodes) != 2 || graph.Nodes[0].Name != "Repository" || graph.Nodes[0].Scope != "prototype" || graph.Nodes[0].Type != "*test.Repository" {
+		t.Fatalf("unexpected nodes %s", data)
+	}
+}
+
+type GraphConsumer struct {
+	Service *Service
+	Repo    UserRepository
+}
+
+func TestGraphBeforeInit(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&GraphConsumer{}), core.NewBean(&Service{}))
+	if dot := app.GraphDOT(); !strings.Contains(dot, `"GraphConsumer" -> "Service"`) || strings.Contains(dot, `-> "Repository"`) {
+		t.Fatalf("graph should be built on registration:\n%s", dot)
+	}
+	app.RegisterBeans(core.NewBean(&Repository{}))
+	if dot := app.GraphDOT(); !strings.Contains(dot, `"Service" -> "Repository"`) {
+		t.Fatalf("graph should be updated when a dependency is registered later:\n%s", dot)
+	}
+	if err := app.Validate(); err == nil || !strings.Contains(err.Error(), "no bean implements test.UserRepository") {
+		t.Fatalf("missing dependency should be reported before startup, got %v", err)
+	}
+	app.RegisterBeans(core.NewBean(&MemoryUserRepository{}))
+	if err := app.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if dot := app.GraphDOT(); !strings.Contains(dot, `"GraphConsumer" -> "MemoryUserRepository"`) {
+		t.Fatalf("graph should resolve interface dependencies registered later:\n%s", dot)
+	}
+}
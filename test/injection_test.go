@@ -0,0 +1,396 @@
+package test
+
+import (
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type UserRepository interface {
+	FindName(id int) string
+}
+
+type MemoryUserRepository struct{}
+
+func (*MemoryUserRepository) FindName(id int) string {
+	return "memory"
+}
+
+type CachedUserRepository struct{}
+
+func (*CachedUserRepository) FindName(id int) string {
+	return "cached"
+}
+
+type UserService struct {
+	Repo UserRepository
+}
+
+func TestInterfaceInjection(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&UserService{}), core.NewBean(&MemoryUserRepository{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	service := app.Container().GetBeanInstanceByName("UserService").(*UserService)
+	if service.Repo == nil || service.Repo.FindName(1) != "memory" {
+		t.Fatal("interface field should be injected with its only implementation")
+	}
+}
+
+func TestInterfaceInjectionCandidates(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&UserService{}))
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "no bean implements test.UserRepository") {
+		t.Fatalf("expected missing implementation error, got %v", err)
+	}
+	app, _ = newTestApplication()
+	app.RegisterBeans(core.NewBean(&UserService{}), core.NewBean(&MemoryUserRepository{}), core.NewBean(&CachedUserRepository{}))
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "[CachedUserRepository, MemoryUserRepository]") {
+		t.Fatalf("expected ambiguous implementation error, got %v", err)
+	}
+}
+
+type DataSource struct {
+	Url string `autowired:"optional"`
+}
+
+type ReportService struct {
+	Primary *DataSource
+	Replica *DataSource    `qualifier:"replica"`
+	Repo    UserRepository `qualifier:"cache"`
+}
+
+func TestPrimaryAndQualifier(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&ReportService{}),
+		core.NewBean(&DataSource{}).SetName("main").SetPrimary(true),
+		core.NewBean(&DataSource{}).SetName("replica"),
+		core.NewBean(&MemoryUserRepository{}),
+		core.NewBean(&CachedUserRepository{}).SetQualifier("cache"),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	service := c.GetBeanInstanceByName("ReportService").(*ReportService)
+	if service.Primary != c.GetBeanInstanceByName("main") || service.Replica != c.GetBeanInstanceByName("replica") {
+		t.Fatal("primary and qualified data sources should be injected")
+	}
+	if service.Repo.FindName(1) != "cached" {
+		t.Fatal("qualified repository should be injected")
+	}
+}
+
+type ReplicaReader struct {
+	Source *DataSource
+}
+
+func TestFactoryParamQualifier(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewFactoryBean(func(source *DataSource) *ReplicaReader {
+			return &ReplicaReader{Source: source}
+		}).SetParamQualifier(0, "replica"),
+		core.NewBean(&DataSource{}).SetName("main").SetPrimary(true),
+		core.NewBean(&DataSource{}).SetName("replica"),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	reader := app.Container().GetBeanInstanceByName("ReplicaReader").(*ReplicaReader)
+	if reader.Source != app.Container().GetBeanInstanceByName("replica") {
+		t.Fatal("qualified factory param should take precedence over the primary bean")
+	}
+}
+
+func TestAmbiguousCandidates(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&ReportService{}),
+		core.NewBean(&DataSource{}).SetName("main"),
+		core.NewBean(&DataSource{}).SetName("replica"),
+		core.NewBean(&CachedUserRepository{}).SetQualifier("cache"),
+	)
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "matches beans [main, replica]") {
+		t.Fatalf("expected ambiguous candidates error, got %v", err)
+	}
+}
+
+type HealthCheck interface {
+	Check() error
+}
+
+type DbHealthCheck struct{}
+
+func (*DbHealthCheck) Check() error {
+	return nil
+}
+
+func (*DbHealthCheck) GetPriority() int {
+	return 1
+}
+
+type CacheHealthCheck struct{}
+
+func (*CacheHealthCheck) Check() error {
+	return nil
+}
+
+type HealthService struct {
+	Checks       []HealthCheck
+	ChecksByName map[string]HealthCheck
+}
+
+func TestCollectionInjection(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&HealthService{}),
+		core.NewBean(&DbHealthCheck{}),
+		core.NewBean(&CacheHealthCheck{}).SetPriority(10),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	service := c.GetBeanInstanceByName("HealthService").(*HealthService)
+	if len(service.Checks) != 2 || service.Checks[0] != c.GetBeanInstanceByName("CacheHealthCheck") || service.Checks[1] != c.GetBeanInstanceByName("DbHealthCheck") {
+		t.Fatalf("slice should contain every check ordered by priority, got %v", service.Checks)
+	}
+	if len(service.ChecksByName) != 2 || service.ChecksByName["DbHealthCheck"] != c.GetBeanInstanceByName("DbHealthCheck") {
+		t.Fatalf("map should be keyed by bean name, got %v", service.ChecksByName)
+	}
+}
+
+type Counter struct {
+	Count int
+}
+
+type LazyConsumer struct {
+	Counter        func() *Counter
+	CounterE       func() (*Counter, error)
+	CounterHandle  core.Provider[*Counter]
+	Repo           core.Provider[UserRepository]
+	MissingHandler func() (HealthCheck, error)
+}
+
+func TestLazyInjection(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&LazyConsumer{}),
+		core.NewBean(&Counter{}).SetIsSingleton(false),
+		core.NewBean(&MemoryUserRepository{}),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	consumer := app.Container().GetBeanInstanceByName("LazyConsumer").(*LazyConsumer)
+	if consumer.Counter() == consumer.Counter() {
+		t.Fatal("prototype bean should be created on every call")
+	}
+	if counter, err := consumer.CounterE(); err != nil || counter == nil {
+		t.Fatalf("expected counter, got %v %v", counter, err)
+	}
+	if counter := consumer.CounterHandle.MustGet(); counter == nil {
+		t.Fatal("expected counter from provider")
+	}
+	if repo := consumer.Repo.MustGet(); repo.FindName(1) != "memory" {
+		t.Fatal("expected repository from provider")
+	}
+	if _, err := consumer.MissingHandler(); err == nil {
+		t.Fatal("expected error for missing lazy dependency")
+	}
+}
+
+type SlowCounter struct {
+	Counter *Counter
+}
+
+func (s *SlowCounter) Init(c *core.Container) {
+	time.Sleep(time.Millisecond)
+}
+
+type ConcurrentConsumer struct {
+	Slow   core.Provider[*SlowCounter]
+	Shared core.Provider[*DataSource]
+}
+
+func TestConcurrentProviderGet(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&ConcurrentConsumer{}),
+		core.NewBean(&SlowCounter{}).SetIsSingleton(false),
+		core.NewBean(&Counter{}).SetIsSingleton(false),
+		core.NewBean(&DataSource{}).SetLazy(true),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	consumer := app.Container().GetBeanInstanceByName("ConcurrentConsumer").(*ConcurrentConsumer)
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	shared := make(chan *DataSource, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := consumer.Slow.Get(); err != nil {
+				errs <- err
+			}
+			dataSource, err := consumer.Shared.Get()
+			if err != nil {
+				errs <- err
+			}
+			shared <- dataSource
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(shared)
+	for err := range errs {
+		t.Fatalf("concurrent creation should not be reported as a circular reference, got %v", err)
+	}
+	first := <-shared
+	for dataSource := range shared {
+		if dataSource != first {
+			t.Fatal("lazy singleton should be created once under concurrent access")
+		}
+	}
+}
+
+type StrictService struct {
+	Name      string `autowired:"optional"`
+	Container *core.Container
+	Db        *DataSource
+	Repo      UserRepository
+	Cache     *Counter `autowired:"optional"`
+	Checks    []HealthCheck
+}
+
+func TestStrictInjection(t *testing.T) {
+	app, _ := newTestApplication(ioc.WithStrictInjection(true))
+	app.RegisterBeans(core.NewBean(&StrictService{}))
+	err := app.Run()
+	if err == nil || !strings.Contains(err.Error(), "unsatisfied dependency field Db") || !strings.Contains(err.Error(), "no bean implements test.UserRepository") {
+		t.Fatalf("every unsatisfied injection point should be reported, got %v", err)
+	}
+	if strings.Contains(err.Error(), "Cache") || strings.Contains(err.Error(), "Checks") {
+		t.Fatalf("optional and collection fields should not be reported, got %v", err)
+	}
+
+	app, _ = newTestApplication(ioc.WithStrictInjection(true))
+	app.RegisterBeans(core.NewBean(&StrictService{}), core.NewBean(&DataSource{}), core.NewBean(&MemoryUserRepository{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	service := app.Container().GetBeanInstanceByName("StrictService").(*StrictService)
+	if service.Cache != nil || service.Container != app.Container() {
+		t.Fatalf("optional field should stay nil, got %+v", service)
+	}
+}
+
+type StrictSettings struct {
+	Timeout int
+	Region  string `autowired:"optional"`
+	Port    int    `autowired:"false"`
+}
+
+func TestStrictInjectionNonBeanFields(t *testing.T) {
+	app, _ := newTestApplication(ioc.WithStrictInjection(true))
+	app.RegisterBeans(core.NewBean(&StrictSettings{}))
+	err := app.Run()
+	if err == nil || !strings.Contains(err.Error(), "unsatisfied dependency field Timeout of type int") {
+		t.Fatalf("non-bean field without config tag should be reported, got %v", err)
+	}
+	if strings.Contains(err.Error(), "Region") || strings.Contains(err.Error(), "Port") {
+		t.Fatalf("optional and skipped non-bean fields should not be reported, got %v", err)
+	}
+
+	app, _ = newTestApplication()
+	app.RegisterBeans(core.NewBean(&StrictSettings{}))
+	if err := app.Run(); err != nil {
+		t.Fatalf("non-bean fields should keep zero values without strict mode, got %v", err)
+	}
+}
+
+func TestDefaultInjectionFabricatesZeroValues(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&StrictService{}), core.NewBean(&MemoryUserRepository{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	service := app.Container().GetBeanInstanceByName("StrictService").(*StrictService)
+	if service.Db == nil || service.Cache != nil {
+		t.Fatalf("non-strict mode should keep fabricating defaults except for optional fields, got %+v", service)
+	}
+}
+
+type PrivateService struct {
+	repo    UserRepository `autowired:"true"`
+	db      *DataSource    `name:"DataSource"`
+	ignored *DataSource
+}
+
+func TestUnexportedFieldInjection(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&PrivateService{}), core.NewBean(&DataSource{}), core.NewBean(&MemoryUserRepository{}))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	service := app.Container().GetBeanInstanceByName("PrivateService").(*PrivateService)
+	if service.repo == nil || service.db != app.Container().GetBeanInstanceByName("DataSource") {
+		t.Fatalf("tagged unexported fields should be injected, got %+v", service)
+	}
+	if service.ignored != nil {
+		t.Fatal("untagged unexported fields should be left alone")
+	}
+}
+
+type LegacyClient struct {
+	Source *DataSource `name:"legacyDataSource"`
+}
+
+func TestBeanAliases(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&LegacyClient{}),
+		core.NewBean(&DataSource{}).SetName("orderDataSource").AddAlias("legacyDataSource", "dataSource"),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	source := c.GetBeanInstanceByName("orderDataSource")
+	if c.GetBeanInstanceByName("legacyDataSource") != source || c.GetBeanInstanceByName("dataSource") != source {
+		t.Fatal("aliases should resolve to the same instance")
+	}
+	if c.GetBeanInstanceByName("LegacyClient").(*LegacyClient).Source != source {
+		t.Fatal("name tag should resolve aliases")
+	}
+}
+
+func TestBeanAliasCollision(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(core.NewBean(&DataSource{}).SetName("main"))
+	assertPanics(t, func() {
+		app.RegisterBeans(core.NewBean(&DataSource{}).SetName("replica").AddAlias("main"))
+	})
+	app.RegisterBeans(core.NewBean(&DataSource{}).SetName("replica").AddAlias("secondary"))
+	assertPanics(t, func() {
+		app.RegisterBeans(core.NewBean(&DataSource{}).SetName("secondary"))
+	})
+}
+
+func assertPanics(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	f()
+}
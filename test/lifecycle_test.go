@@ -0,0 +1,121 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/kgip/go-spring/core"
+	"strings"
+	"testing"
+)
+
+type LifecycleLog struct {
+	Steps []string
+}
+
+func (l *LifecycleLog) String() string {
+	return strings.Join(l.Steps, ",")
+}
+
+// lifecycleBean 记录启动和停止顺序的Lifecycle
+type lifecycleBean struct {
+	name    string
+	log     *LifecycleLog
+	running bool
+	failure error
+}
+
+func (b *lifecycleBean) Start(ctx context.Context) error {
+	if b.failure != nil {
+		return b.failure
+	}
+	b.running = true
+	b.log.Steps = append(b.log.Steps, "start "+b.name)
+	return nil
+}
+
+func (b *lifecycleBean) Stop(ctx context.Context) error {
+	b.running = false
+	b.log.Steps = append(b.log.Steps, "stop "+b.name)
+	return nil
+}
+
+func (b *lifecycleBean) IsRunning() bool {
+	return b.running
+}
+
+type MessageConsumer struct {
+	lifecycleBean
+}
+
+type HttpServer struct {
+	lifecycleBean
+	phase       int
+	autoStartup bool
+	Consumer    *MessageConsumer
+}
+
+func (s *HttpServer) GetPhase() int {
+	return s.phase
+}
+
+func (s *HttpServer) IsAutoStartup() bool {
+	return s.autoStartup
+}
+
+func newLifecycleBeans(log *LifecycleLog) (*MessageConsumer, *HttpServer, *HttpServer) {
+	consumer := &MessageConsumer{lifecycleBean{name: "consumer", log: log}}
+	server := &HttpServer{lifecycleBean: lifecycleBean{name: "server", log: log}, phase: -1, autoStartup: true}
+	scheduler := &HttpServer{lifecycleBean: lifecycleBean{name: "scheduler", log: log}, phase: 10}
+	return consumer, server, scheduler
+}
+
+func TestLifecyclePhases(t *testing.T) {
+	app, _ := newTestApplication()
+	log := &LifecycleLog{}
+	consumer, server, scheduler := newLifecycleBeans(log)
+	app.RegisterBeans(
+		core.NewFactoryBean(func() *MessageConsumer { return consumer }),
+		core.NewFactoryBean(func() *HttpServer { return server }).SetName("server"),
+		core.NewFactoryBean(func() *HttpServer { return scheduler }).SetName("scheduler"),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if log.String() != "start consumer,start server" {
+		t.Fatalf("dependency should start before the lifecycle bean depending on it, got %s", log)
+	}
+	if server.Consumer != consumer {
+		t.Fatal("lifecycle bean should be injected like other beans")
+	}
+	if scheduler.IsRunning() {
+		t.Fatal("bean without auto startup should not be started")
+	}
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	log.Steps = nil
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if log.String() != "stop scheduler,stop server,stop consumer" {
+		t.Fatalf("running lifecycle beans should stop in reverse start order, got %s", log)
+	}
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	app, _ := newTestApplication()
+	log := &LifecycleLog{}
+	consumer, server, _ := newLifecycleBeans(log)
+	server.failure = errors.New("address already in use")
+	app.RegisterBeans(
+		core.NewFactoryBean(func() *MessageConsumer { return consumer }),
+		core.NewFactoryBean(func() *HttpServer { return server }).SetName("server"),
+	)
+	err := app.Run()
+	if err == nil || !strings.Contains(err.Error(), "address already in use") {
+		t.Fatalf("start failure should fail the container, got %v", err)
+	}
+	if log.String() != "start consumer,stop consumer" || consumer.IsRunning() {
+		t.Fatalf("started lifecycle beans should be stopped after a start failure, got %s", log)
+	}
+}
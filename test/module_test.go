@@ -0,0 +1,74 @@
+package test
+
+import (
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+	"strings"
+	"testing"
+)
+
+type testModule struct {
+	name         string
+	dependencies []string
+	defaults     map[string]interface{}
+	beans        []func() *core.Bean
+	installed    *[]string
+}
+
+func (m *testModule) Name() string {
+	return m.name
+}
+
+func (m *testModule) DependsOn() []string {
+	return m.dependencies
+}
+
+func (m *testModule) Defaults() map[string]interface{} {
+	return m.defaults
+}
+
+func (m *testModule) Register(app *ioc.Application) {
+	*m.installed = append(*m.installed, m.name)
+	for _, bean := range m.beans {
+		app.RegisterBeans(bean())
+	}
+}
+
+func TestModules(t *testing.T) {
+	app, config := newTestApplication()
+	config.configs["modules.redis.enabled"] = false
+	config.configs["db.enabled"] = true
+	var installed []string
+	app.RegisterModules(
+		&testModule{name: "metrics", dependencies: []string{"db"}, installed: &installed},
+		&testModule{name: "cache", dependencies: []string{"redis"}, installed: &installed,
+			beans: []func() *core.Bean{func() *core.Bean { return core.NewBean(&MemoryCache{}) }}},
+		&testModule{name: "redis", installed: &installed, defaults: map[string]interface{}{"redis.addr": "localhost:6379"},
+			beans: []func() *core.Bean{func() *core.Bean { return core.NewBean(&RedisClient{}) }}},
+		ioc.When(&testModule{name: "db", installed: &installed,
+			beans: []func() *core.Bean{func() *core.Bean { return core.NewBean(&DataSource{}) }}}, core.OnProperty("db.enabled", "true")),
+	)
+	if strings.Join(installed, ",") != "db,metrics,redis,cache" {
+		t.Fatalf("modules should be registered in dependency order, got %v", installed)
+	}
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	if c.GetBeanInstanceByName("RedisClient") != nil || c.GetBeanInstanceByName("MemoryCache") != nil {
+		t.Fatal("disabled module and its dependents should not register beans")
+	}
+	if c.GetBeanInstanceByName("DataSource") == nil || c.GetConfiguration().GetConfig("redis.addr") != "localhost:6379" {
+		t.Fatal("enabled module beans and module defaults should be available")
+	}
+}
+
+func TestModuleUnknownDependency(t *testing.T) {
+	app, _ := newTestApplication()
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(error).Error(), "depends on unknown module redis") {
+			t.Fatalf("expected unknown module error, got %v", r)
+		}
+	}()
+	app.RegisterModules(&testModule{name: "cache", dependencies: []string{"redis"}})
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"github.com/kgip/go-spring/configuration"
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/ioc"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type FakeMailSender struct{}
+
+type SmtpMailSender struct{}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("mail:\n  host: smtp.example.com\n  port: 25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config-dev.yaml"), []byte("mail:\n  host: localhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configuration.ProfilesEnv, "dev, test")
+	app := ioc.NewApplication(ioc.WithConfigPath(path), ioc.WithLogger(log.New(io.Discard, "", 0)))
+	app.RegisterBeans(
+		core.NewBean(&FakeMailSender{}).SetProfiles("dev"),
+		core.NewBean(&SmtpMailSender{}).SetProfiles("prod"),
+		core.NewBean(&Counter{}).SetProfiles("!prod"),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	c := app.Container()
+	if profiles := c.ActiveProfiles(); len(profiles) != 2 || profiles[0] != "dev" || profiles[1] != "test" {
+		t.Fatalf("unexpected active profiles %v", profiles)
+	}
+	config := c.GetConfiguration()
+	if config.GetConfig("mail.host") != "localhost" || config.GetConfig("mail.port") != 25 {
+		t.Fatalf("profile configuration should overlay base configuration, got %v %v", config.GetConfig("mail.host"), config.GetConfig("mail.port"))
+	}
+	if c.GetBeanInstanceByName("FakeMailSender") == nil || c.GetBeanInstanceByName("Counter") == nil || c.GetBeanInstanceByName("SmtpMailSender") != nil {
+		t.Fatal("beans should be registered according to active profiles")
+	}
+}
+
+func TestProfileConfigRefresh(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("mail:\n  host: smtp.example.com\n  port: 25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	overlay := filepath.Join(dir, "config-dev.yaml")
+	if err := os.WriteFile(overlay, []byte("mail:\n  host: localhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := configuration.NewConfiguration(path, "yaml", true, log.New(io.Discard, "", 0))
+	config.SetActiveProfiles("dev")
+	changed := make(chan struct{}, 16)
+	config.OnChange(func() {
+		changed <- struct{}{}
+	})
+	config.Load()
+	waitConfig := func(key string, expected interface{}) {
+		t.Helper()
+		deadline := time.After(2 * time.Second)
+		for config.GetConfig(key) != expected {
+			select {
+			case <-changed:
+			case <-deadline:
+				t.Fatalf("%s should be reloaded as %v, got %v", key, expected, config.GetConfig(key))
+			}
+		}
+	}
+	if err := os.WriteFile(path, []byte("mail:\n  host: smtp.example.com\n  port: 2525\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitConfig("mail.port", 2525)
+	if config.GetConfig("mail.host") != "localhost" {
+		t.Fatalf("profile overlay should survive reloading the base configuration, got %v", config.GetConfig("mail.host"))
+	}
+	if err := os.WriteFile(overlay, []byte("mail:\n  host: mail.local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitConfig("mail.host", "mail.local")
+}
+
+func TestConfigWatcherClosedOnShutdown(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("mail:\n  port: 25\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := configuration.NewConfiguration(path, "yaml", true, log.New(io.Discard, "", 0))
+	changed := make(chan struct{}, 16)
+	config.OnChange(func() {
+		changed <- struct{}{}
+	})
+	app := ioc.NewApplication(ioc.WithConfiguration(config), ioc.WithLogger(log.New(io.Discard, "", 0)))
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("mail:\n  port: 2525\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+		t.Fatal("configuration should not be reloaded after the container is shut down")
+	case <-time.After(300 * time.Millisecond):
+	}
+	if config.GetConfig("mail.port") != 25 {
+		t.Fatalf("configuration should keep the last loaded values, got %v", config.GetConfig("mail.port"))
+	}
+}
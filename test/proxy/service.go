@@ -0,0 +1,32 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+)
+
+//go:generate sh -c "cd ../../cmd/go-spring && go run . proxy ../../test/proxy"
+
+// GreetService 问候服务
+// go-spring:proxy
+type GreetService interface {
+	Greet(ctx context.Context, name string) (string, error)
+	Join(sep string, names ...string) string
+	Reset()
+}
+
+type DefaultGreetService struct {
+	Resets int
+}
+
+func (s *DefaultGreetService) Greet(ctx context.Context, name string) (string, error) {
+	return "hello " + name, nil
+}
+
+func (s *DefaultGreetService) Join(sep string, names ...string) string {
+	return strings.Join(names, sep)
+}
+
+func (s *DefaultGreetService) Reset() {
+	s.Resets++
+}
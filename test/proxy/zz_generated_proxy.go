@@ -0,0 +1,63 @@
+// Code generated by go-spring proxy. DO NOT EDIT.
+
+package proxy
+
+import (
+	"context"
+	"github.com/kgip/go-spring/core"
+	"reflect"
+)
+
+func init() {
+	core.RegisterProxy[GreetService](func(target GreetService, interceptors []core.Interceptor) GreetService {
+		return &greetServiceProxy{target: target, interceptors: interceptors}
+	})
+}
+
+// greetServiceProxy 接口GreetService的代理,方法调用经过拦截链后再调用目标bean
+type greetServiceProxy struct {
+	target       GreetService
+	interceptors []core.Interceptor
+}
+
+func (proxy *greetServiceProxy) Greet(p0 context.Context, p1 string) (string, error) {
+	method := &core.Method{Interface: reflect.TypeOf((*GreetService)(nil)).Elem(), Name: "Greet", Target: proxy.target}
+	results := core.InvokeChain(p0, method, proxy.interceptors, []interface{}{p0, p1}, func(args []interface{}) []interface{} {
+		a0, _ := args[0].(context.Context)
+		a1, _ := args[1].(string)
+		r0, r1 := proxy.target.Greet(a0, a1)
+		return []interface{}{r0, r1}
+	})
+	var r0 string
+	if len(results) > 0 {
+		r0, _ = results[0].(string)
+	}
+	var r1 error
+	if len(results) > 1 {
+		r1, _ = results[1].(error)
+	}
+	return r0, r1
+}
+
+func (proxy *greetServiceProxy) Join(p0 string, p1 ...string) string {
+	method := &core.Method{Interface: reflect.TypeOf((*GreetService)(nil)).Elem(), Name: "Join", Target: proxy.target}
+	results := core.InvokeChain(context.Background(), method, proxy.interceptors, []interface{}{p0, p1}, func(args []interface{}) []interface{} {
+		a0, _ := args[0].(string)
+		a1, _ := args[1].([]string)
+		r0 := proxy.target.Join(a0, a1...)
+		return []interface{}{r0}
+	})
+	var r0 string
+	if len(results) > 0 {
+		r0, _ = results[0].(string)
+	}
+	return r0
+}
+
+func (proxy *greetServiceProxy) Reset() {
+	method := &core.Method{Interface: reflect.TypeOf((*GreetService)(nil)).Elem(), Name: "Reset", Target: proxy.target}
+	core.InvokeChain(context.Background(), method, proxy.interceptors, []interface{}{}, func(args []interface{}) []interface{} {
+		proxy.target.Reset()
+		return nil
+	})
+}
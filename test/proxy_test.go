@@ -0,0 +1,189 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"github.com/kgip/go-spring/core"
+	"github.com/kgip/go-spring/gen"
+	"github.com/kgip/go-spring/test/proxy"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type TracingInterceptor struct {
+	Calls []string
+}
+
+func (i *TracingInterceptor) GetPriority() int {
+	return 1
+}
+
+func (i *TracingInterceptor) Invoke(ctx context.Context, method *core.Method, args []interface{}) []interface{} {
+	i.Calls = append(i.Calls, method.String())
+	return method.Proceed(ctx, args)
+}
+
+type UpperInterceptor struct{}
+
+func (UpperInterceptor) Invoke(ctx context.Context, method *core.Method, args []interface{}) []interface{} {
+	results := method.Proceed(ctx, args)
+	if method.Name == "Greet" {
+		results[0] = strings.ToUpper(results[0].(string))
+	}
+	return results
+}
+
+type Greeter struct {
+	Service proxy.GreetService
+}
+
+func TestInterceptorProxy(t *testing.T) {
+	app, _ := newTestApplication()
+	target := &proxy.DefaultGreetService{}
+	app.RegisterBeans(
+		core.NewBean(&Greeter{}),
+		core.NewBean(&TracingInterceptor{}),
+		core.NewFactoryBean(func() UpperInterceptor { return UpperInterceptor{} }).SetName("upper"),
+		core.NewFactoryBean(func() proxy.GreetService { return target }).SetName("greetService"),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	greeter := app.Container().GetBeanInstanceByName("Greeter").(*Greeter)
+	if greeter.Service == proxy.GreetService(target) {
+		t.Fatal("bean exposed through a proxied interface should be replaced by its proxy")
+	}
+	if message, err := greeter.Service.Greet(context.Background(), "spring"); err != nil || message != "HELLO SPRING" {
+		t.Fatalf("interceptors should wrap the call, got %s %v", message, err)
+	}
+	if joined := greeter.Service.Join(",", "a", "b"); joined != "a,b" {
+		t.Fatalf("variadic arguments should be passed through, got %s", joined)
+	}
+	greeter.Service.Reset()
+	tracing := app.Container().GetBeanInstanceByName("TracingInterceptor").(*TracingInterceptor)
+	if len(tracing.Calls) != 3 || target.Resets != 1 {
+		t.Fatalf("every method should pass the interceptor chain, got %v", tracing.Calls)
+	}
+}
+
+func TestStructBeanProxy(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&Greeter{}),
+		core.NewFactoryBean(func() UpperInterceptor { return UpperInterceptor{} }).SetName("upper"),
+		core.NewBean(&proxy.DefaultGreetService{}),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	greeter := app.Container().GetBeanInstanceByName("Greeter").(*Greeter)
+	if _, ok := greeter.Service.(*proxy.DefaultGreetService); ok {
+		t.Fatal("struct bean injected through a proxied interface should be replaced by its proxy")
+	}
+	if message, _ := greeter.Service.Greet(context.Background(), "spring"); message != "HELLO SPRING" {
+		t.Fatalf("interceptors should wrap the call of struct bean, got %s", message)
+	}
+	greeter.Service.Reset()
+	target := app.Container().GetBeanInstanceByName("DefaultGreetService").(*proxy.DefaultGreetService)
+	if target.Resets != 1 {
+		t.Fatal("proxy should call the struct bean instance")
+	}
+}
+
+type CyclicGreetService struct {
+	proxy.GreetService `autowired:"false"`
+	Greeter            *CyclicGreeter
+}
+
+type CyclicGreeter struct {
+	Service proxy.GreetService
+}
+
+func TestEarlyReferenceProxy(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewFactoryBean(func() proxy.GreetService {
+			return &CyclicGreetService{GreetService: &proxy.DefaultGreetService{}}
+		}).SetName("greetService"),
+		core.NewBean(&CyclicGreeter{}),
+		core.NewFactoryBean(func() UpperInterceptor { return UpperInterceptor{} }).SetName("upper"),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	service := app.Container().GetBeanInstanceByName("greetService").(proxy.GreetService)
+	greeter := app.Container().GetBeanInstanceByName("CyclicGreeter").(*CyclicGreeter)
+	if greeter.Service != service {
+		t.Fatal("cycle partner should receive the same proxy as the container")
+	}
+	if message, _ := greeter.Service.Greet(context.Background(), "spring"); message != "HELLO SPRING" {
+		t.Fatalf("early reference should be intercepted, got %s", message)
+	}
+}
+
+type ShortCircuitInterceptor struct{}
+
+func (ShortCircuitInterceptor) Invoke(ctx context.Context, method *core.Method, args []interface{}) []interface{} {
+	return nil
+}
+
+func TestShortCircuitInterceptor(t *testing.T) {
+	app, _ := newTestApplication()
+	app.RegisterBeans(
+		core.NewBean(&Greeter{}),
+		core.NewFactoryBean(func() ShortCircuitInterceptor { return ShortCircuitInterceptor{} }).SetName("shortCircuit"),
+		core.NewBean(&proxy.DefaultGreetService{}),
+	)
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	greeter := app.Container().GetBeanInstanceByName("Greeter").(*Greeter)
+	if message, err := greeter.Service.Greet(context.Background(), "spring"); message != "" || err != nil {
+		t.Fatalf("proxy should return zero values when interceptor skips the target, got %s %v", message, err)
+	}
+}
+
+func TestGeneratedProxyUpToDate(t *testing.T) {
+	source, err := gen.Proxies("proxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := os.ReadFile("proxy/" + gen.ProxyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, generated) {
+		t.Fatal("generated proxy is out of date, run go generate ./test/proxy")
+	}
+}
+
+func TestGeneratedProxyImportConflict(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"html.go": "package views\n\nimport \"html/template\"\n\n// Page go-spring:proxy\ntype Page interface {\n\tRender(t *template.Template) error\n}\n",
+		"text.go": "package views\n\nimport (\n\tcore \"net/http\"\n\t\"text/template\"\n)\n\n// Mail go-spring:proxy\ntype Mail interface {\n\tRender(t *template.Template, h core.Handler) error\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source, err := gen.Proxies(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"\t\"html/template\"\n",
+		"\ttemplate2 \"text/template\"\n",
+		"\tcore2 \"net/http\"\n",
+		"\t\"github.com/kgip/go-spring/core\"\n",
+		"Render(p0 *template2.Template, p1 core2.Handler) error",
+		"Render(p0 *template.Template) error",
+	} {
+		if !strings.Contains(string(source), expected) {
+			t.Fatalf("generated proxy should contain %q, got:\n%s", expected, source)
+		}
+	}
+}